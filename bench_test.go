@@ -1,6 +1,9 @@
 package iterset
 
 import (
+	"cmp"
+	"context"
+	"fmt"
 	"iter"
 	"maps"
 	"math/rand"
@@ -288,3 +291,197 @@ func BenchmarkSortedSlice(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkSyncMapSet(b *testing.B) {
+	var s SyncMapSet[int, struct{}]
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			key := rand.Intn(size)
+			if i%4 == 0 {
+				s.Add(key)
+			} else {
+				s.Contains(key)
+			}
+		}
+	})
+}
+
+func BenchmarkShardedMapSet(b *testing.B) {
+	s := NewShardedMapSet[int, struct{}](0)
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			key := rand.Intn(size)
+			if i%4 == 0 {
+				s.Add(key)
+			} else {
+				s.Contains(key)
+			}
+		}
+	})
+}
+
+func BenchmarkGoCount(b *testing.B) {
+	_, k := setup(b)
+	slc := slices.Collect(k)
+	b.Run("slice", func(b *testing.B) {
+		for b.Loop() {
+			GoCount[int](slc, 0)
+		}
+	})
+	b.Run("seq", func(b *testing.B) {
+		for b.Loop() {
+			GoCount[int](k, 0)
+		}
+	})
+}
+
+func BenchmarkGoGroupBy(b *testing.B) {
+	_, k := setup(b)
+	slc := slices.Collect(k)
+	for b.Loop() {
+		GoGroupBy(slc, identity[int], 0)
+	}
+}
+
+func BenchmarkGoIter(b *testing.B) {
+	_, k := setup(b)
+	for b.Loop() {
+		for range GoIter(context.Background(), k, 0) {
+		}
+	}
+}
+
+func BenchmarkGoBatch(b *testing.B) {
+	_, k := setup(b)
+	for b.Loop() {
+		for range GoBatch(context.Background(), k, 0, 0, false) {
+		}
+	}
+}
+
+func BenchmarkGoBatchFlush(b *testing.B) {
+	_, k := setup(b)
+	for b.Loop() {
+		for range GoBatch(context.Background(), k, 0, 0, true) {
+		}
+	}
+}
+
+func BenchmarkSortedMerge(b *testing.B) {
+	seqs := make([][]int, 16)
+	for i := range seqs {
+		_, k := setup(b)
+		seqs[i] = slices.Sorted(k)
+	}
+	b.Run("chained", func(b *testing.B) {
+		for b.Loop() {
+			keys := slices.Values(seqs[0])
+			for _, seq := range seqs[1:] {
+				keys = SortedUnion(keys, seq)
+			}
+			for range keys {
+			}
+		}
+	})
+	b.Run("k-way", func(b *testing.B) {
+		for b.Loop() {
+			for range SortedMerge(slices.Values(seqs[0]), seqs[1:]...) {
+			}
+		}
+	})
+}
+
+func BenchmarkSortedIntersectAll(b *testing.B) {
+	seqs := make([][]int, 8)
+	for i := range seqs {
+		_, k := setup(b)
+		seqs[i] = slices.Sorted(k)
+	}
+	sparse := slices.Clone(seqs)
+	sparse[len(sparse)-1] = nil
+	for i := 0; i < len(seqs[0]); i += 100 {
+		sparse[len(sparse)-1] = append(sparse[len(sparse)-1], seqs[len(seqs)-1][i])
+	}
+	for name, seqs := range map[string][][]int{"dense": seqs, "sparse": sparse} {
+		b.Run(name+"/nested", func(b *testing.B) {
+			for b.Loop() {
+				keys := slices.Values(seqs[0])
+				for _, seq := range seqs[1:] {
+					keys = SortedIntersect(keys, seq)
+				}
+				for range keys {
+				}
+			}
+		})
+		b.Run(name+"/leapfrog", func(b *testing.B) {
+			for b.Loop() {
+				for range SortedIntersect(slices.Values(seqs[0]), seqs[1:]...) {
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSortedGallop(b *testing.B) {
+	values := make([]int, size*10)
+	for i := range values {
+		values[i] = rand.Intn(size * 100)
+	}
+	slices.Sort(values)
+	for _, ratio := range []int{1, 2, 4, 8, 16, 64, 1024} {
+		keys := make([]int, len(values)/ratio)
+		for i := range keys {
+			keys[i] = rand.Intn(size * 100)
+		}
+		slices.Sort(keys)
+		for _, probes := range []int{0, gallop, len(values)} {
+			b.Run(fmt.Sprintf("ratio=%d/probes=%d", ratio, probes), func(b *testing.B) {
+				for b.Loop() {
+					i := 0
+					for _, key := range keys {
+						if i = seek(values, i, key, cmp.Compare[int], probes); i == len(values) {
+							break
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkSortedMap(b *testing.B) {
+	_, k := setup(b)
+	for b.Loop() {
+		var s SortedSet[int]
+		s.Insert(k, struct{}{})
+		for key := range k {
+			s.Rank(key)
+		}
+		s.Remove(k)
+	}
+}
+
+func BenchmarkBitSet(b *testing.B) {
+	s, k := setup(b)
+	b1, b2 := NewBitSet(maps.Keys(s)), NewBitSet(k)
+	for b.Loop() {
+		b1.Intersect(b2).Len()
+	}
+}
+
+func BenchmarkBitmap(b *testing.B) {
+	s, k := setup(b)
+	b1 := NewBitmap(func(yield func(uint32) bool) {
+		for key := range s {
+			yield(uint32(key))
+		}
+	})
+	b2 := NewBitmap(func(yield func(uint32) bool) {
+		for key := range k {
+			yield(uint32(key))
+		}
+	})
+	for b.Loop() {
+		b1.Intersect(b2).Len()
+	}
+}
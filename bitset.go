@@ -0,0 +1,236 @@
+package iterset
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// BitSet is a set of non-negative integers stored as a dense bitmap,
+// which is much smaller and faster than a [MapSet] when keys are in a compact range.
+// The zero value is an empty set ready to use.
+//
+// Set operations between bitsets are word-parallel, and [BitSet.All] is compatible with any
+// function which accepts an [iter.Seq].
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet returns a [BitSet] of the keys, e.g., from [maps.Keys] of a [MapSet].
+func NewBitSet(keys iter.Seq[int]) *BitSet {
+	b := &BitSet{}
+	b.Insert(keys)
+	return b
+}
+
+// trim returns the words without trailing zeros.
+func (b *BitSet) trim() []uint64 {
+	i := len(b.words)
+	for i > 0 && b.words[i-1] == 0 {
+		i--
+	}
+	return b.words[:i]
+}
+
+func (b *BitSet) word(i int) uint64 {
+	if i < len(b.words) {
+		return b.words[i]
+	}
+	return 0
+}
+
+// Len returns the number of keys.
+//
+// Performance:
+//   - time: O(n/64)
+func (b *BitSet) Len() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Contains returns whether the key is present.
+func (b *BitSet) Contains(key int) bool {
+	return key >= 0 && b.word(key/64)&(1<<(key%64)) != 0
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (b *BitSet) Missing(key int) bool {
+	return !b.Contains(key)
+}
+
+// Add key(s). Panics if a key is negative.
+func (b *BitSet) Add(keys ...int) {
+	for _, key := range keys {
+		b.add(key)
+	}
+}
+
+func (b *BitSet) add(key int) {
+	if key < 0 {
+		panic("iterset: negative BitSet key")
+	}
+	if i := key / 64; i >= len(b.words) {
+		b.words = append(b.words, make([]uint64, i+1-len(b.words))...)
+	}
+	b.words[key/64] |= 1 << (key % 64)
+}
+
+// Insert keys. Panics if a key is negative.
+func (b *BitSet) Insert(keys iter.Seq[int]) {
+	for key := range keys {
+		b.add(key)
+	}
+}
+
+// Delete key(s).
+func (b *BitSet) Delete(keys ...int) {
+	for _, key := range keys {
+		b.delete(key)
+	}
+}
+
+func (b *BitSet) delete(key int) {
+	if key >= 0 && key/64 < len(b.words) {
+		b.words[key/64] &^= 1 << (key % 64)
+	}
+}
+
+// Remove keys.
+func (b *BitSet) Remove(keys iter.Seq[int]) {
+	for key := range keys {
+		b.delete(key)
+	}
+}
+
+// Toggle removes present keys, and adds missing keys.
+func (b *BitSet) Toggle(keys iter.Seq[int]) {
+	for key := range keys {
+		if b.Contains(key) {
+			b.delete(key)
+		} else {
+			b.add(key)
+		}
+	}
+}
+
+// All returns the keys in ascending order.
+func (b *BitSet) All() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, w := range b.words {
+			for ; w != 0; w &= w - 1 {
+				if !yield(i*64 + bits.TrailingZeros64(w)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MapSet returns the keys as a [MapSet].
+func (b *BitSet) MapSet() MapSet[int, struct{}] {
+	m := make(MapSet[int, struct{}], b.Len())
+	for key := range b.All() {
+		m.add(key)
+	}
+	return m
+}
+
+// Clone returns a copy.
+func (b *BitSet) Clone() *BitSet {
+	return &BitSet{slices.Clone(b.trim())}
+}
+
+// Equal returns whether the sets are equivalent.
+func (b *BitSet) Equal(other *BitSet) bool {
+	return slices.Equal(b.trim(), other.trim())
+}
+
+// IsSubset returns whether every key is present in the other.
+func (b *BitSet) IsSubset(other *BitSet) bool {
+	for i, w := range b.words {
+		if w&^other.word(i) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDisjoint returns whether no keys are present in both.
+func (b *BitSet) IsDisjoint(other *BitSet) bool {
+	for i := range min(len(b.words), len(other.words)) {
+		if b.words[i]&other.words[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new set of the keys present in either.
+//
+// Performance:
+//   - time: O(max(n1, n2)/64)
+func (b *BitSet) Union(other *BitSet) *BitSet {
+	if len(b.words) < len(other.words) {
+		b, other = other, b
+	}
+	words := slices.Clone(b.words)
+	for i, w := range other.words {
+		words[i] |= w
+	}
+	return &BitSet{words}
+}
+
+// Intersect returns a new set of the keys present in both.
+//
+// Performance:
+//   - time: O(min(n1, n2)/64)
+func (b *BitSet) Intersect(other *BitSet) *BitSet {
+	words := make([]uint64, min(len(b.words), len(other.words)))
+	for i := range words {
+		words[i] = b.words[i] & other.words[i]
+	}
+	return &BitSet{words}
+}
+
+// IntersectCount returns the number of keys present in both.
+//
+// Performance:
+//   - time: O(min(n1, n2)/64)
+func (b *BitSet) IntersectCount(other *BitSet) int {
+	count := 0
+	for i := range min(len(b.words), len(other.words)) {
+		count += bits.OnesCount64(b.words[i] & other.words[i])
+	}
+	return count
+}
+
+// Difference returns a new set of the keys which are not present in the other.
+//
+// Performance:
+//   - time: O(n1/64)
+func (b *BitSet) Difference(other *BitSet) *BitSet {
+	words := slices.Clone(b.words)
+	for i := range words {
+		words[i] &^= other.word(i)
+	}
+	return &BitSet{words}
+}
+
+// SymmetricDifference returns a new set of the keys which are not present in both.
+//
+// Performance:
+//   - time: O(max(n1, n2)/64)
+func (b *BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	if len(b.words) < len(other.words) {
+		b, other = other, b
+	}
+	words := slices.Clone(b.words)
+	for i, w := range other.words {
+		words[i] ^= w
+	}
+	return &BitSet{words}
+}
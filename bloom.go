@@ -0,0 +1,165 @@
+package iterset
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/maphash"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// ErrMismatch is returned when combining probabilistic sets with different parameters.
+var ErrMismatch = errors.New("iterset: mismatched parameters")
+
+// seed is shared by default hashes, so filters in the same process are compatible.
+var seed = maphash.MakeSeed()
+
+// BloomFilter is a probabilistic set which may report false positives, but not false negatives.
+// Keys are hashed with double hashing to set multiple bits.
+//
+// The default hash is [maphash.Comparable] with a seed which is random per process,
+// so a filter which is serialized and used by another process requires a deterministic hash,
+// passed to [NewBloomFilter] and [UnmarshalBloomFilter].
+// The zero value must be unmarshaled before use, and then has the default hash.
+type BloomFilter[K comparable] struct {
+	words []uint64
+	k     int
+	hash  func(K) uint64
+}
+
+// NewBloomFilter returns an empty [BloomFilter] sized for n keys with a false positive rate p.
+// If hash is nil, the default hash is used. Panics if p is not in (0, 1).
+func NewBloomFilter[K comparable](n int, p float64, hash func(K) uint64) *BloomFilter[K] {
+	if p <= 0 || p >= 1 {
+		panic("iterset: false positive rate must be in (0, 1)")
+	}
+	m := math.Ceil(-float64(max(n, 1)) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := max(int(math.Round(m/float64(max(n, 1))*math.Ln2)), 1)
+	return &BloomFilter[K]{words: make([]uint64, int(m+63)/64), k: k, hash: hash}
+}
+
+// indexes returns the bit positions of the key.
+func (f *BloomFilter[K]) indexes(key K) iter.Seq[uint64] {
+	var h uint64
+	if f.hash == nil {
+		h = maphash.Comparable(seed, key)
+	} else {
+		h = f.hash(key)
+	}
+	h2, m := bits.RotateLeft64(h, 32)|1, uint64(len(f.words))*64
+	return func(yield func(uint64) bool) {
+		for i := range uint64(f.k) {
+			if !yield((h + i*h2) % m) {
+				return
+			}
+		}
+	}
+}
+
+// Contains returns whether the key may be present.
+func (f *BloomFilter[K]) Contains(key K) bool {
+	for i := range f.indexes(key) {
+		if f.words[i/64]&(1<<(i%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing returns whether the key is definitely not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (f *BloomFilter[K]) Missing(key K) bool {
+	return !f.Contains(key)
+}
+
+// Add key(s).
+func (f *BloomFilter[K]) Add(keys ...K) {
+	for _, key := range keys {
+		f.add(key)
+	}
+}
+
+func (f *BloomFilter[K]) add(key K) {
+	for i := range f.indexes(key) {
+		f.words[i/64] |= 1 << (i % 64)
+	}
+}
+
+// Insert keys.
+func (f *BloomFilter[K]) Insert(keys iter.Seq[K]) {
+	for key := range keys {
+		f.add(key)
+	}
+}
+
+func (f *BloomFilter[K]) combine(
+	other *BloomFilter[K], op func(uint64, uint64) uint64,
+) (*BloomFilter[K], error) {
+	if f.k != other.k || len(f.words) != len(other.words) {
+		return nil, ErrMismatch
+	}
+	words := slices.Clone(f.words)
+	for i, w := range other.words {
+		words[i] = op(words[i], w)
+	}
+	return &BloomFilter[K]{words: words, k: f.k, hash: f.hash}, nil
+}
+
+// Union returns a new filter of the keys which may be present in either.
+// Returns [ErrMismatch] if the filters were sized differently.
+// The filters must also use the same hash.
+func (f *BloomFilter[K]) Union(other *BloomFilter[K]) (*BloomFilter[K], error) {
+	return f.combine(other, func(w1, w2 uint64) uint64 { return w1 | w2 })
+}
+
+// Intersect returns a new filter of the keys which may be present in both.
+// Returns [ErrMismatch] if the filters were sized differently.
+// The filters must also use the same hash.
+// The false positive rate may be higher than a filter of the intersection.
+func (f *BloomFilter[K]) Intersect(other *BloomFilter[K]) (*BloomFilter[K], error) {
+	return f.combine(other, func(w1, w2 uint64) uint64 { return w1 & w2 })
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] with a portable little-endian format:
+// the number of hashes, and the number of words followed by the words.
+// The hash function is not included.
+func (f *BloomFilter[K]) MarshalBinary() ([]byte, error) {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(f.k))
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(f.words)))
+	for _, w := range f.words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBloomFilter returns a filter from data in the format of [BloomFilter.MarshalBinary],
+// with the given hash, which must be the hash the filter was built with.
+// If hash is nil, the default hash is used. Returns [ErrEncoding] if the data is invalid.
+func UnmarshalBloomFilter[K comparable](data []byte, hash func(K) uint64) (*BloomFilter[K], error) {
+	f := &BloomFilter[K]{hash: hash}
+	if err := f.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler], retaining the hash function.
+// Returns [ErrEncoding] if the data is invalid.
+func (f *BloomFilter[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return ErrEncoding
+	}
+	k, size := int(binary.LittleEndian.Uint32(data)), int(binary.LittleEndian.Uint32(data[4:]))
+	data = data[8:]
+	if k == 0 || size == 0 || len(data) != 8*size {
+		return ErrEncoding
+	}
+	words := make([]uint64, size)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	f.words, f.k = words, k
+	return nil
+}
@@ -0,0 +1,495 @@
+package iterset
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// degree is the minimum degree of B-tree nodes, which have degree-1..2*degree-1 keys.
+const degree = 16
+
+// SortedMap is a map which iterates in sorted key order, backed by a B-tree.
+// Each node stores its subtree size, so ranking and selecting are also O(log n).
+// The zero value is an empty map ready to use.
+// The map must not be modified during iteration.
+//
+// Its sorted keys are compatible with the Sorted* functions, e.g., [SortedUnion].
+type SortedMap[K cmp.Ordered, V any] struct {
+	root *node[K, V]
+}
+
+// SortedSet is a [SortedMap] with empty values.
+type SortedSet[K cmp.Ordered] = SortedMap[K, struct{}]
+
+type node[K cmp.Ordered, V any] struct {
+	keys     []K
+	values   []V
+	children []*node[K, V] // nil for leaves
+	size     int
+}
+
+func (n *node[K, V]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[K, V]) leaf() bool {
+	return n.children == nil
+}
+
+func (n *node[K, V]) full() bool {
+	return len(n.keys) == 2*degree-1
+}
+
+// split moves the upper half of a full child into a new sibling, and its median into the parent.
+func (n *node[K, V]) split(i int) {
+	y := n.children[i]
+	z := &node[K, V]{keys: slices.Clone(y.keys[degree:]), values: slices.Clone(y.values[degree:])}
+	if !y.leaf() {
+		z.children = slices.Clone(y.children[degree:])
+	}
+	z.size = len(z.keys)
+	for _, child := range z.children {
+		z.size += child.size
+	}
+	n.keys = slices.Insert(n.keys, i, y.keys[degree-1])
+	n.values = slices.Insert(n.values, i, y.values[degree-1])
+	n.children = slices.Insert(n.children, i+1, z)
+	clear(y.keys[degree-1:])
+	clear(y.values[degree-1:])
+	y.keys, y.values = y.keys[:degree-1], y.values[:degree-1]
+	if !y.leaf() {
+		clear(y.children[degree:])
+		y.children = y.children[:degree]
+	}
+	y.size -= z.size + 1
+}
+
+// merge moves a key and its right child into its left child.
+func (n *node[K, V]) merge(i int) {
+	y, z := n.children[i], n.children[i+1]
+	y.keys = append(append(y.keys, n.keys[i]), z.keys...)
+	y.values = append(append(y.values, n.values[i]), z.values...)
+	y.children = append(y.children, z.children...)
+	y.size += z.size + 1
+	n.keys = slices.Delete(n.keys, i, i+1)
+	n.values = slices.Delete(n.values, i, i+1)
+	n.children = slices.Delete(n.children, i+1, i+2)
+}
+
+// fill ensures the child has more than the minimum number of keys, so a key can be deleted.
+// Returns the index of the child, which may have been merged to the left.
+func (n *node[K, V]) fill(i int) int {
+	c := n.children[i]
+	switch {
+	case len(c.keys) >= degree:
+	case i > 0 && len(n.children[i-1].keys) >= degree:
+		l := n.children[i-1]
+		last := len(l.keys) - 1
+		c.keys = slices.Insert(c.keys, 0, n.keys[i-1])
+		c.values = slices.Insert(c.values, 0, n.values[i-1])
+		n.keys[i-1], n.values[i-1] = l.keys[last], l.values[last]
+		l.keys, l.values = slices.Delete(l.keys, last, last+1), slices.Delete(l.values, last, last+1)
+		moved := 1
+		if !l.leaf() {
+			child := l.children[last+1]
+			c.children = slices.Insert(c.children, 0, child)
+			l.children = slices.Delete(l.children, last+1, last+2)
+			moved += child.size
+		}
+		c.size += moved
+		l.size -= moved
+	case i < len(n.keys) && len(n.children[i+1].keys) >= degree:
+		r := n.children[i+1]
+		c.keys = append(c.keys, n.keys[i])
+		c.values = append(c.values, n.values[i])
+		n.keys[i], n.values[i] = r.keys[0], r.values[0]
+		r.keys, r.values = slices.Delete(r.keys, 0, 1), slices.Delete(r.values, 0, 1)
+		moved := 1
+		if !r.leaf() {
+			child := r.children[0]
+			c.children = append(c.children, child)
+			r.children = slices.Delete(r.children, 0, 1)
+			moved += child.size
+		}
+		c.size += moved
+		r.size -= moved
+	case i < len(n.keys):
+		n.merge(i)
+	default:
+		n.merge(i - 1)
+		return i - 1
+	}
+	return i
+}
+
+// delete removes a key which is known to be present in the subtree.
+func (n *node[K, V]) delete(key K) {
+	n.size -= 1
+	i, found := slices.BinarySearch(n.keys, key)
+	switch {
+	case found && n.leaf():
+		n.keys = slices.Delete(n.keys, i, i+1)
+		n.values = slices.Delete(n.values, i, i+1)
+	case found && len(n.children[i].keys) >= degree:
+		y := n.children[i]
+		for !y.leaf() {
+			y = y.children[len(y.keys)]
+		}
+		last := len(y.keys) - 1
+		n.keys[i], n.values[i] = y.keys[last], y.values[last]
+		n.children[i].delete(y.keys[last])
+	case found && len(n.children[i+1].keys) >= degree:
+		z := n.children[i+1]
+		for !z.leaf() {
+			z = z.children[0]
+		}
+		n.keys[i], n.values[i] = z.keys[0], z.values[0]
+		n.children[i+1].delete(z.keys[0])
+	case found:
+		n.merge(i)
+		n.children[i].delete(key)
+	default:
+		n.children[n.fill(i)].delete(key)
+	}
+}
+
+func (n *node[K, V]) all(yield func(K, V) bool) bool {
+	for i := range n.keys {
+		if !n.leaf() && !n.children[i].all(yield) {
+			return false
+		}
+		if !yield(n.keys[i], n.values[i]) {
+			return false
+		}
+	}
+	return n.leaf() || n.children[len(n.keys)].all(yield)
+}
+
+func (n *node[K, V]) backward(yield func(K, V) bool) bool {
+	if !n.leaf() && !n.children[len(n.keys)].backward(yield) {
+		return false
+	}
+	for i := len(n.keys) - 1; i >= 0; i-- {
+		if !yield(n.keys[i], n.values[i]) {
+			return false
+		}
+		if !n.leaf() && !n.children[i].backward(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// ascend yields the pairs with keys >= lo.
+func (n *node[K, V]) ascend(lo K, yield func(K, V) bool) bool {
+	i, _ := slices.BinarySearch(n.keys, lo)
+	if !n.leaf() && !n.children[i].ascend(lo, yield) {
+		return false
+	}
+	for ; i < len(n.keys); i++ {
+		if !yield(n.keys[i], n.values[i]) {
+			return false
+		}
+		if !n.leaf() && !n.children[i+1].all(yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// child returns the child which would contain the key, or nil for leaves.
+func (n *node[K, V]) child(key K) *node[K, V] {
+	if n.leaf() {
+		return nil
+	}
+	i, _ := slices.BinarySearch(n.keys, key)
+	return n.children[i]
+}
+
+func (s *SortedMap[K, V]) find(key K) (*node[K, V], int) {
+	for n := s.root; n != nil; {
+		i, found := slices.BinarySearch(n.keys, key)
+		if found {
+			return n, i
+		}
+		if n.leaf() {
+			break
+		}
+		n = n.children[i]
+	}
+	return nil, 0
+}
+
+// Len returns the number of keys.
+func (s *SortedMap[K, V]) Len() int {
+	return s.root.len()
+}
+
+// Get returns the value and whether the key is present.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Get(key K) (V, bool) {
+	n, i := s.find(key)
+	if n == nil {
+		var zero V
+		return zero, false
+	}
+	return n.values[i], true
+}
+
+// Contains returns whether the key is present.
+func (s *SortedMap[K, V]) Contains(key K) bool {
+	n, _ := s.find(key)
+	return n != nil
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (s *SortedMap[K, V]) Missing(key K) bool {
+	return !s.Contains(key)
+}
+
+// IsSuperset returns whether all keys are present.
+func (s *SortedMap[K, V]) IsSuperset(keys iter.Seq[K]) bool {
+	return allFunc(keys, s.Contains)
+}
+
+// Store sets the value of the key.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Store(key K, value V) {
+	if n, i := s.find(key); n != nil {
+		n.values[i] = value
+		return
+	}
+	if s.root == nil {
+		s.root = &node[K, V]{}
+	}
+	if s.root.full() {
+		s.root = &node[K, V]{children: []*node[K, V]{s.root}, size: s.root.size}
+		s.root.split(0)
+	}
+	n := s.root
+	for {
+		n.size += 1
+		i, _ := slices.BinarySearch(n.keys, key)
+		if n.leaf() {
+			n.keys = slices.Insert(n.keys, i, key)
+			n.values = slices.Insert(n.values, i, value)
+			return
+		}
+		if n.children[i].full() {
+			n.split(i)
+			if cmp.Less(n.keys[i], key) {
+				i += 1
+			}
+		}
+		n = n.children[i]
+	}
+}
+
+// Add key(s) with zero value.
+func (s *SortedMap[K, V]) Add(keys ...K) {
+	var value V
+	for _, key := range keys {
+		s.Store(key, value)
+	}
+}
+
+// Insert keys with default value.
+func (s *SortedMap[K, V]) Insert(keys iter.Seq[K], value V) {
+	for key := range keys {
+		s.Store(key, value)
+	}
+}
+
+// Delete key(s).
+//
+// Performance:
+//   - time: O(log n) per key
+func (s *SortedMap[K, V]) Delete(keys ...K) {
+	for _, key := range keys {
+		if !s.Contains(key) {
+			continue
+		}
+		s.root.delete(key)
+		if len(s.root.keys) > 0 {
+			continue
+		}
+		if s.root.leaf() {
+			s.root = nil
+		} else {
+			s.root = s.root.children[0]
+		}
+	}
+}
+
+// Remove keys.
+func (s *SortedMap[K, V]) Remove(keys iter.Seq[K]) {
+	for key := range keys {
+		s.Delete(key)
+	}
+}
+
+// Keep only the keys present in both.
+//
+// Performance:
+//   - time: O(k log n + n)
+//   - space: O(n)
+func (s *SortedMap[K, V]) Keep(keys iter.Seq[K]) {
+	m := Set[K]()
+	for key := range keys {
+		if s.Contains(key) {
+			m.add(key)
+		}
+	}
+	if len(m) < s.Len() {
+		s.Delete(slices.Collect(m.ReverseDifference(s.Keys()))...)
+	}
+}
+
+// All returns the key-value pairs in ascending key order.
+func (s *SortedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if s.root != nil {
+			s.root.all(yield)
+		}
+	}
+}
+
+// Keys returns the keys in ascending order.
+func (s *SortedMap[K, V]) Keys() iter.Seq[K] {
+	return Keys(s.All())
+}
+
+// Backward returns the key-value pairs in descending key order.
+func (s *SortedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if s.root != nil {
+			s.root.backward(yield)
+		}
+	}
+}
+
+// Range returns the key-value pairs with lo <= key < hi, in ascending key order.
+//
+// Performance:
+//   - time: O(log n + k)
+func (s *SortedMap[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if s.root != nil {
+			s.root.ascend(lo, func(key K, value V) bool {
+				return cmp.Less(key, hi) && yield(key, value)
+			})
+		}
+	}
+}
+
+// Min returns the smallest key and its value, and whether the map is not empty.
+func (s *SortedMap[K, V]) Min() (K, V, bool) {
+	return s.Select(0)
+}
+
+// Max returns the largest key and its value, and whether the map is not empty.
+func (s *SortedMap[K, V]) Max() (K, V, bool) {
+	return s.Select(s.Len() - 1)
+}
+
+// Floor returns the largest key <= key, its value, and whether it exists.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Floor(key K) (K, V, bool) {
+	var k K
+	var v V
+	ok := false
+	for n := s.root; n != nil; n = n.child(key) {
+		i, found := slices.BinarySearch(n.keys, key)
+		if found {
+			return n.keys[i], n.values[i], true
+		}
+		if i > 0 {
+			k, v, ok = n.keys[i-1], n.values[i-1], true
+		}
+	}
+	return k, v, ok
+}
+
+// Ceiling returns the smallest key >= key, its value, and whether it exists.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	var k K
+	var v V
+	ok := false
+	for n := s.root; n != nil; n = n.child(key) {
+		i, found := slices.BinarySearch(n.keys, key)
+		if found || i < len(n.keys) {
+			k, v, ok = n.keys[i], n.values[i], true
+		}
+		if found {
+			break
+		}
+	}
+	return k, v, ok
+}
+
+// Rank returns the number of keys < key.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Rank(key K) int {
+	rank := 0
+	for n := s.root; n != nil; {
+		i, found := slices.BinarySearch(n.keys, key)
+		rank += i
+		if n.leaf() {
+			break
+		}
+		for _, child := range n.children[:i] {
+			rank += child.size
+		}
+		if found {
+			return rank + n.children[i].size
+		}
+		n = n.children[i]
+	}
+	return rank
+}
+
+// Select returns the key at the index in sorted order, its value, and whether it exists.
+//
+// Performance:
+//   - time: O(log n)
+func (s *SortedMap[K, V]) Select(index int) (K, V, bool) {
+	if index < 0 || index >= s.Len() {
+		var k K
+		var v V
+		return k, v, false
+	}
+	n := s.root
+	for {
+		i := 0
+		for ; i < len(n.keys); i++ {
+			size := 0
+			if !n.leaf() {
+				size = n.children[i].size
+			}
+			if index == size {
+				return n.keys[i], n.values[i], true
+			}
+			if index < size {
+				break
+			}
+			index -= size + 1
+		}
+		n = n.children[i]
+	}
+}
@@ -0,0 +1,184 @@
+package iterset
+
+import (
+	"iter"
+	"maps"
+)
+
+// Counter is a multiset of keys with positive counts, which can be converted from [Count].
+// Operations retain only positive counts, so keys are removed when their count reaches zero.
+//
+// A counter is a map, so [maps.Equal] compares counts, and [Counter.Elements] is compatible
+// with [EqualCounts].
+type Counter[K comparable] map[K]int
+
+// Elements returns each key repeated by its count, in arbitrary order.
+//
+// Performance:
+//   - time: O(Total)
+func (c Counter[K]) Elements() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key, count := range c {
+			for range count {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Total returns the sum of the counts.
+func (c Counter[K]) Total() int {
+	total := 0
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
+// MostCommon returns the n keys with the highest counts, in descending order of count.
+// If n < 0 or n >= the number of keys, all keys are returned.
+// Ties are broken arbitrarily by map iteration order,
+// so which of the tied keys are included at the boundary of n may vary between calls.
+//
+// Performance:
+//   - time: O(m log n)
+//   - space: O(n)
+func (c Counter[K]) MostCommon(n int) []K {
+	if n < 0 || n > len(c) {
+		n = len(c)
+	}
+	less := func(e1, e2 counted[K]) bool { return e1.count < e2.count }
+	h := heap[counted[K]]{values: make([]counted[K], 0, n), less: less}
+	for key, count := range c {
+		if len(h.values) < n {
+			h.push(counted[K]{key, count})
+		} else if n > 0 && count > h.values[0].count {
+			h.set(0, counted[K]{key, count})
+		}
+	}
+	keys := make([]K, len(h.values))
+	for i := len(keys) - 1; i >= 0; i-- {
+		keys[i] = h.pop().key
+	}
+	return keys
+}
+
+// counted is a key with its count, for ranking by heap.
+type counted[K any] struct {
+	key   K
+	count int
+}
+
+// Add increments the count of each key.
+func (c Counter[K]) Add(keys iter.Seq[K]) {
+	for key := range keys {
+		c[key] += 1
+	}
+}
+
+// Subtract decrements the count of each key, removing keys which reach zero.
+func (c Counter[K]) Subtract(keys iter.Seq[K]) {
+	for key := range keys {
+		c.subtract(key, 1)
+	}
+}
+
+func (c Counter[K]) clone() Counter[K] {
+	m := make(Counter[K], len(c))
+	maps.Copy(m, c)
+	return m
+}
+
+func (c Counter[K]) subtract(key K, count int) {
+	if c[key] > count {
+		c[key] -= count
+	} else {
+		delete(c, key)
+	}
+}
+
+// AddCounts increments the count of each key by its count.
+func (c Counter[K]) AddCounts(counts map[K]int) {
+	for key, count := range counts {
+		if count > 0 {
+			c[key] += count
+		}
+	}
+}
+
+// SubtractCounts decrements the count of each key by its count, removing keys which reach zero.
+func (c Counter[K]) SubtractCounts(counts map[K]int) {
+	for key, count := range counts {
+		if count > 0 {
+			c.subtract(key, count)
+		}
+	}
+}
+
+// Sum returns a new counter with the counts added.
+//
+// Related:
+//   - [Counter.AddCounts] to update in-place
+//
+// Performance:
+//   - time: O(m+n)
+func (c Counter[K]) Sum(counts ...map[K]int) Counter[K] {
+	c = c.clone()
+	for _, counts := range counts {
+		c.AddCounts(counts)
+	}
+	return c
+}
+
+// Union returns a new counter with the maximum of the counts.
+//
+// Performance:
+//   - time: O(m+n)
+func (c Counter[K]) Union(counts ...map[K]int) Counter[K] {
+	c = c.clone()
+	for _, counts := range counts {
+		for key, count := range counts {
+			if count > c[key] {
+				c[key] = count
+			}
+		}
+	}
+	return c
+}
+
+// Intersect returns a new counter with the minimum of the counts of keys present in both.
+//
+// Performance:
+//   - time: O(min(m, n))
+func (c Counter[K]) Intersect(counts map[K]int) Counter[K] {
+	c1, c2 := map[K]int(c), counts
+	if len(c2) > len(c1) {
+		c1, c2 = c2, c1
+	}
+	m := Counter[K]{}
+	for key, count := range c2 {
+		if count = min(count, c1[key]); count > 0 {
+			m[key] = count
+		}
+	}
+	return m
+}
+
+// Difference returns a new counter with the counts subtracted, retaining only positive counts.
+//
+// Related:
+//   - [Counter.SubtractCounts] to update in-place
+//
+// Performance:
+//   - time: O(m)
+func (c Counter[K]) Difference(counts map[K]int) Counter[K] {
+	m := Counter[K]{}
+	for key, count := range c {
+		if count -= max(counts[key], 0); count > 0 {
+			m[key] = count
+		}
+	}
+	return m
+}
@@ -0,0 +1,172 @@
+package iterset
+
+import (
+	"cmp"
+	"hash/maphash"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// CountMinSketch is a probabilistic counter which may overestimate counts, but not underestimate,
+// in constant space. Keys are hashed with double hashing to a counter in each row,
+// and the estimate is the minimum of the counters.
+//
+// With width w and depth d, the error is at most e / w * total with probability 1 - e^-d.
+// In conservative mode, only the minimum counters are incremented, which reduces the error
+// but requires counts to be non-negative, and precludes subtraction.
+type CountMinSketch[K comparable] struct {
+	counts       []int
+	width        int
+	conservative bool
+	hash         func(K) uint64
+}
+
+// NewCountMinSketch returns an empty [CountMinSketch] with an error of at most epsilon * total,
+// with probability 1 - delta. If hash is nil, the default hash is used.
+// Panics if epsilon or delta is not in (0, 1).
+//
+// Related:
+//   - [Count] for exact counts
+func NewCountMinSketch[K comparable](
+	epsilon, delta float64, conservative bool, hash func(K) uint64,
+) *CountMinSketch[K] {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		panic("iterset: epsilon and delta must be in (0, 1)")
+	}
+	width := int(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	return &CountMinSketch[K]{
+		counts: make([]int, width*depth), width: width, conservative: conservative, hash: hash,
+	}
+}
+
+// indexes returns the counter positions of the key, one per row.
+func (s *CountMinSketch[K]) indexes(key K) iter.Seq[int] {
+	var h uint64
+	if s.hash == nil {
+		h = maphash.Comparable(seed, key)
+	} else {
+		h = s.hash(key)
+	}
+	h2, w := bits.RotateLeft64(h, 32)|1, uint64(s.width)
+	return func(yield func(int) bool) {
+		for i := range len(s.counts) / s.width {
+			if !yield(i*s.width + int((h+uint64(i)*h2)%w)) {
+				return
+			}
+		}
+	}
+}
+
+// Estimate returns the estimated count of the key, which is never less than the actual count.
+//
+// Performance:
+//   - time: O(d)
+func (s *CountMinSketch[K]) Estimate(key K) int {
+	count := math.MaxInt
+	for i := range s.indexes(key) {
+		count = min(count, s.counts[i])
+	}
+	return count
+}
+
+// Add increments the count of the key by n, and returns the new estimate.
+func (s *CountMinSketch[K]) Add(key K, n int) int {
+	if !s.conservative {
+		count := math.MaxInt
+		for i := range s.indexes(key) {
+			s.counts[i] += n
+			count = min(count, s.counts[i])
+		}
+		return count
+	}
+	count := s.Estimate(key) + n
+	for i := range s.indexes(key) {
+		s.counts[i] = max(s.counts[i], count)
+	}
+	return count
+}
+
+// Insert increments the count of each key.
+func (s *CountMinSketch[K]) Insert(keys iter.Seq[K]) {
+	for key := range keys {
+		s.Add(key, 1)
+	}
+}
+
+// Merge adds the counts of the other sketch, in-place.
+// Returns [ErrMismatch] if the sketches were sized differently.
+// The sketches must also use the same hash.
+// Merged conservative sketches remain overestimates, but with the error of a standard sketch.
+func (s *CountMinSketch[K]) Merge(other *CountMinSketch[K]) error {
+	if s.width != other.width || len(s.counts) != len(other.counts) {
+		return ErrMismatch
+	}
+	for i, count := range other.counts {
+		s.counts[i] += count
+	}
+	return nil
+}
+
+// TopK tracks the approximate heavy hitters of a stream, using a [CountMinSketch] for estimates.
+// It retains the k keys with the highest estimates seen so far, in a min-heap.
+type TopK[K comparable] struct {
+	sketch *CountMinSketch[K]
+	k      int
+	index  map[K]int
+	heap   heap[counted[K]]
+}
+
+// NewTopK returns an empty [TopK] of k keys, which adds counts to the sketch.
+//
+// Related:
+//   - [Max] of [Count] for the exact most common keys
+func NewTopK[K comparable](k int, sketch *CountMinSketch[K]) *TopK[K] {
+	t := &TopK[K]{sketch: sketch, k: k, index: map[K]int{}}
+	t.heap.less = func(a, b counted[K]) bool { return a.count < b.count }
+	t.heap.moved = func(c counted[K], i int) { t.index[c.key] = i }
+	return t
+}
+
+// Add increments the count of the key by n, and returns the new estimate.
+//
+// Performance:
+//   - time: O(d + log(k))
+func (t *TopK[K]) Add(key K, n int) int {
+	count := t.sketch.Add(key, n)
+	if i, ok := t.index[key]; ok {
+		t.heap.set(i, counted[K]{key, count})
+	} else if len(t.heap.values) < t.k {
+		t.heap.push(counted[K]{key, count})
+	} else if t.k > 0 && count > t.heap.values[0].count {
+		delete(t.index, t.heap.values[0].key)
+		t.heap.set(0, counted[K]{key, count})
+	}
+	return count
+}
+
+// Insert increments the count of each key.
+func (t *TopK[K]) Insert(keys iter.Seq[K]) {
+	for key := range keys {
+		t.Add(key, 1)
+	}
+}
+
+// All returns the keys and estimated counts, in descending order of counts.
+//
+// Performance:
+//   - time: O(k log(k))
+func (t *TopK[K]) All() iter.Seq2[K, int] {
+	values := slices.SortedStableFunc(slices.Values(t.heap.values), func(a, b counted[K]) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	return func(yield func(K, int) bool) {
+		for _, c := range values {
+			if !yield(c.key, c.count) {
+				return
+			}
+		}
+	}
+}
@@ -1,12 +1,20 @@
 package iterset
 
 import (
+	"cmp"
 	"context"
+	"encoding/binary"
+	"errors"
 	"iter"
 	"maps"
+	"math"
+	"math/rand"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestBreak(t *testing.T) {
@@ -46,15 +54,39 @@ func TestBreak(t *testing.T) {
 	for range SortedUnion(k, slices.Values([]string{""})) {
 		break
 	}
+	for range SortedMerge(k, slices.Values([]string{""})) {
+		break
+	}
+	for range SortedSymmetricDifference(k, []string{""}) {
+		break
+	}
+	for range SortedSymmetricDifference(k, []string{"b"}) {
+		break
+	}
 	for range SortedIntersect(k, slices.Values([]string{"a"})) {
 		break
 	}
 	for range SortedDifference(k, slices.Values([]string{"a"})) {
 		break
 	}
+	for range SortedDifference(k, []string{"b"}) {
+		break
+	}
+	for range CheckSorted(k) {
+		break
+	}
 	for range GoIter(context.Background(), slices.Values([]string{"", ""}), 0) {
 		break
 	}
+	for range GoBatch(context.Background(), slices.Values([]string{"", "", ""}), 0, 2, false) {
+		break
+	}
+	for range GoMap(context.Background(), k, 1, strings.ToUpper) {
+		break
+	}
+	for range GoMapUnordered(context.Background(), k, 1, strings.ToUpper) {
+		break
+	}
 }
 
 func TestExit(t *testing.T) {
@@ -75,6 +107,26 @@ func TestExit(t *testing.T) {
 	for c := range goChan(ctx, k, 1) {
 		t.Errorf("should be canceled: %s", c)
 	}
+	for _, flush := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		count := 0
+		for range GoBatch(ctx, k, 0, 1, flush) {
+			count += 1
+			cancel()
+		}
+		cancel()
+		if count != 1 {
+			t.Errorf("should be canceled: %d", count)
+		}
+	}
+	for _, flush := range []bool{false, true} {
+		for c := range goBatch(ctx, k, 0, 1, flush) {
+			t.Errorf("should be canceled: %s", c)
+		}
+		for c := range goBatch(ctx, k, 0, 3, flush) {
+			t.Errorf("should be canceled: %s", c)
+		}
+	}
 }
 
 func TestEmpty(t *testing.T) {
@@ -118,4 +170,1234 @@ func TestMulti(t *testing.T) {
 	assertMulti(t, Keys(CompactBy(k, strings.ToLower)))
 	assertMulti(t, Keys(Set("b").Difference(k)))
 	assertMulti(t, Set("b").SymmetricDifference(k))
+	assertMulti(t, SortedMerge(k, k))
+	assertMulti(t, SortedIntersect(k, k, k))
+	assertMulti(t, SortedDifference(k, k, k))
+	assertMulti(t, Keys(SortedSymmetricDifference(k, k)))
+	assertMulti(t, CheckSorted(slices.Values([]string{"a", "b"})))
+}
+
+func TestSyncMapSet(t *testing.T) {
+	var s SyncMapSet[string, int]
+	k := slices.Values([]string{"a", "b"})
+	if s.Len() != 0 || s.Contains("a") || !s.Missing("a") || len(s.Clone()) != 0 {
+		t.Error("should be empty")
+	}
+	s.Insert(k, 1)
+	if !s.IsSuperset(k) || !s.Equal(k) || !s.IsSubset(k) || s.IsDisjoint(k) {
+		t.Error("should be equal")
+	}
+	if s.IntersectCount(k) != 2 || len(s.Union(maps.All(map[string]int{"c": 2}))) != 3 {
+		t.Error("should be counted")
+	}
+	if l, b, r := s.Overlap(slices.Values([]string{"b", "c"})); l != 1 || b != 1 || r != 1 {
+		t.Error("should overlap")
+	}
+	s.Toggle(slices.Values([]string{"b", "c"}), 2)
+	if m := maps.Collect(s.All()); len(m) != 2 || m["c"] != 2 {
+		t.Errorf("should be toggled: %v", m)
+	}
+	for key := range s.Difference(slices.Values([]string{"a"})) {
+		s.Delete(key)
+	}
+	if !slices.Equal(slices.Collect(s.ReverseDifference(k)), []string{"b"}) {
+		t.Error("should be reversed")
+	}
+	if !slices.Equal(slices.Collect(s.SymmetricDifference(k)), []string{"b"}) {
+		t.Error("should be symmetric")
+	}
+	s.Keep(slices.Values([]string{"b"}))
+	s.Remove(k)
+	if s.Len() != 0 {
+		t.Error("should be empty")
+	}
+	s.Insert(k, 0)
+	used := false
+	once := func(yield func(string) bool) { // single-use
+		if used {
+			return
+		}
+		used = true
+		for key := range k {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+	if !s.RemoveAll(slices.Values([]string{"a"})) || s.Contains("a") || s.Len() != 1 {
+		t.Error("should remove all")
+	}
+	s.Insert(k, 0)
+	if !s.RemoveAll(once) || s.Len() != 0 {
+		t.Errorf("should remove single-use keys: %v", s.Clone())
+	}
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Go(func() {
+			s.Add(strconv.Itoa(i % 10))
+			s.AddIfMissing(strconv.Itoa(i), i)
+			for range s.All() {
+				break
+			}
+			s.Delete(strconv.Itoa(i % 3))
+		})
+	}
+	wg.Wait()
+	if s.Len() > 100 {
+		t.Error("should be bounded")
+	}
+	s.Add("a")
+	for range s.All() {
+		break
+	}
+	for range s.Intersect(k) {
+		break
+	}
+	for range s.ReverseDifference(k) {
+		break
+	}
+}
+
+func TestShardedMapSet(t *testing.T) {
+	s := NewShardedMapSet[string, int](3)
+	k := slices.Values([]string{"a", "b"})
+	s.Add("a")
+	if v, ok := s.AddIfMissing("b", 1); !ok || v != 1 || !s.IsSuperset(k) || s.Missing("a") {
+		t.Error("should be added")
+	}
+	if !slices.Equal(slices.Collect(s.ReverseDifference(slices.Values([]string{"b", "c"}))), []string{"c"}) {
+		t.Error("should be reversed")
+	}
+	if m := maps.Collect(s.Intersect(slices.Values([]string{"b", "c"}))); len(m) != 1 || m["b"] != 1 {
+		t.Errorf("should be intersected: %v", m)
+	}
+	for range s.All() {
+		break
+	}
+	for range s.Intersect(k) {
+		break
+	}
+	for range s.Difference(k) {
+		t.Error("should be empty")
+	}
+	for range s.Difference(slices.Values([]string{})) {
+		break
+	}
+	for range s.ReverseDifference(slices.Values([]string{"c"})) {
+		break
+	}
+	s.Delete("a")
+	s.Remove(k)
+	if s.Len() != 0 {
+		t.Error("should be empty")
+	}
+}
+
+func TestGoCollect(t *testing.T) {
+	values := make([]int, 20*batchSize+1)
+	for i := range values {
+		values[i] = i % 7
+	}
+	indices := make([]int, len(values))
+	strs := make([]string, len(values))
+	for i := range values {
+		indices[i] = i
+		strs[i] = strconv.Itoa(i)
+	}
+	key := func(i int) int { return i % 3 }
+	length := func(s string) int { return len(s) }
+	concat := func(s1, s2 string) string { return s1 + s2 }
+	expected := Reduce(func(yield func(int, string) bool) {
+		for _, s := range strs {
+			yield(length(s), s)
+		}
+	}, concat)
+	for _, workers := range []int{0, 1, 5} {
+		if !maps.Equal(GoCount[int](values, workers), Count(slices.Values(values))) {
+			t.Error("should count slices")
+		}
+		if !maps.Equal(GoCount[int](slices.Values(values), workers), Count(slices.Values(values))) {
+			t.Error("should count sequences")
+		}
+		if !maps.Equal(GoIndexBy(indices, key, workers), IndexBy(indices, key)) {
+			t.Error("should index slices in order")
+		}
+		if !maps.Equal(GoIndexBy(slices.Values(indices), key, workers), IndexBy(indices, key)) {
+			t.Error("should index sequences in order")
+		}
+		if !maps.EqualFunc(GoGroupBy(indices, key, workers), GroupBy(indices, key), slices.Equal) {
+			t.Error("should group slices in order")
+		}
+		groups := GoGroupBy(slices.Values(indices), key, workers)
+		if !maps.EqualFunc(groups, GroupBy(indices, key), slices.Equal) {
+			t.Error("should group sequences in order")
+		}
+		if !maps.Equal(GoReduce(strs, length, concat, workers), expected) {
+			t.Error("should reduce slices in order")
+		}
+		if !maps.Equal(GoReduce(slices.Values(strs), length, concat, workers), expected) {
+			t.Error("should reduce sequences in order")
+		}
+	}
+}
+
+func TestGoBatch(t *testing.T) {
+	release, blocked := make(chan struct{}), false
+	seq := func(yield func(int) bool) {
+		if yield(1) && yield(2) {
+			select { // blocks while holding a partial batch
+			case <-release:
+			case <-time.After(time.Second):
+				blocked = true
+			}
+		}
+	}
+	for value := range GoBatch(context.Background(), seq, 0, 100, true) {
+		if value == 2 {
+			close(release)
+		}
+	}
+	if blocked {
+		t.Error("should flush while producer is blocked")
+	}
+}
+
+func TestGoMap(t *testing.T) {
+	values := make([]int, 1000)
+	for i := range values {
+		values[i] = i
+	}
+	square := func(i int) int { return i * i }
+	expected := slices.Collect(GoMap(context.Background(), slices.Values(values), 0, identity[int]))
+	if !slices.Equal(expected, values) {
+		t.Error("should retain order")
+	}
+	unordered := slices.Sorted(GoMapUnordered(context.Background(), slices.Values(values), 3, square))
+	for i, value := range unordered {
+		if value != square(i) {
+			t.Fatal("should map all values")
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	for range GoMap(ctx, slices.Values(values), 3, square) {
+		count += 1
+		if count == 10 {
+			cancel()
+		}
+	}
+	if count != 10 {
+		t.Errorf("should be canceled: %d", count)
+	}
+	count = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	for range GoMapUnordered(ctx, slices.Values(values), 3, square) {
+		count += 1
+		if count == 10 {
+			cancel()
+		}
+	}
+	if count != 10 {
+		t.Errorf("should be canceled: %d", count)
+	}
+	called := make(chan int, 3)
+	track := func(i int) int {
+		called <- i
+		return i
+	}
+	for range GoMapUnordered(context.Background(), slices.Values(values), 1, track) {
+		<-called
+		<-called
+		<-called
+		break
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	block := func(i int) int {
+		<-ctx.Done()
+		return i
+	}
+	go cancel()
+	for range GoMap(ctx, slices.Values(values), 3, block) {
+		t.Error("should be canceled")
+	}
+}
+
+func TestGoIterErr(t *testing.T) {
+	k := slices.Values([]string{"a", "b", "c"})
+	for range GoIter2(context.Background(), maps.All(Set("a", "b")), 0) {
+		break
+	}
+	produce := func(yield func(string) bool) error {
+		for value := range k {
+			if !yield(value) {
+				return nil
+			}
+		}
+		return nil
+	}
+	s, err := GoIterErr(context.Background(), produce, 0)
+	for range s {
+		break
+	}
+	if err() != nil {
+		t.Error("should not report an early exit")
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	cause := errors.New("cause")
+	s, err = GoIterErr(ctx, produce, 0)
+	for range s {
+		cancel(cause)
+	}
+	if err() != cause {
+		t.Errorf("should report cancellation: %v", err())
+	}
+	s, err = GoIterErr(ctx, produce, 0)
+	for range s {
+		t.Error("should be canceled")
+	}
+	if err() != cause {
+		t.Errorf("should report cancellation: %v", err())
+	}
+}
+
+func TestSortedMerge(t *testing.T) {
+	seqs := make([][]int, 5)
+	for i := range seqs {
+		for range rand.Intn(20) {
+			seqs[i] = append(seqs[i], rand.Intn(10))
+		}
+		slices.Sort(seqs[i])
+	}
+	expected := slices.Sorted(slices.Values(slices.Concat(seqs...)))
+	merged := slices.Collect(SortedMerge(slices.Values(seqs[0]), seqs[1:]...))
+	if !slices.Equal(merged, expected) {
+		t.Errorf("should be merged: %v", merged)
+	}
+	unique := slices.Collect(SortedMergeUnique(slices.Values(seqs[0]), seqs[1:]...))
+	if !slices.Equal(unique, slices.Compact(expected)) {
+		t.Errorf("should be unique: %v", unique)
+	}
+}
+
+func TestSortedAll(t *testing.T) {
+	for range 100 {
+		seqs := make([][]int, 4)
+		for i := range seqs {
+			for range rand.Intn(20) {
+				seqs[i] = append(seqs[i], rand.Intn(10))
+			}
+			slices.Sort(seqs[i])
+		}
+		keys := slices.Values(seqs[0])
+		intersect, difference := keys, keys
+		for _, seq := range seqs[1:] {
+			intersect = SortedIntersect(intersect, seq)
+			difference = SortedDifference(difference, seq)
+		}
+		actual := slices.Collect(SortedIntersect(keys, seqs[1:]...))
+		if !slices.Equal(actual, slices.Collect(intersect)) {
+			t.Fatalf("should intersect %v: %v", seqs, actual)
+		}
+		actual = slices.Collect(SortedDifference(keys, seqs[1:]...))
+		if !slices.Equal(actual, slices.Collect(difference)) {
+			t.Fatalf("should differ %v: %v", seqs, actual)
+		}
+		left := slices.Collect(SortedDifference(keys, seqs[1]))
+		right := slices.Collect(SortedDifference(slices.Values(seqs[1]), seqs[0]))
+		actual = slices.Collect(Keys(SortedSymmetricDifference(keys, seqs[1])))
+		if !slices.Equal(actual, slices.Sorted(slices.Values(slices.Concat(left, right)))) {
+			t.Fatalf("should be symmetric %v: %v", seqs, actual)
+		}
+	}
+	k := slices.Values([]string{"a", "b"})
+	for key, index := range SortedSymmetricDifference(k, []string{"c"}) {
+		if (key == "c") != (index == 1) {
+			t.Errorf("should be tagged: %s %d", key, index)
+		}
+	}
+	if !slices.Equal(slices.Collect(SortedIntersect[string, []string](k)), []string{"a", "b"}) {
+		t.Error("should be identity")
+	}
+	if !slices.Equal(slices.Collect(SortedDifference[string, []string](k)), []string{"a", "b"}) {
+		t.Error("should be identity")
+	}
+	for range SortedIntersect(k, k, k) {
+		break
+	}
+	for range SortedDifference(k, []string{}, []string{}) {
+		break
+	}
+}
+
+func TestGallop(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 10_000} {
+		keys, values := make([]int, n/10+1), make([]int, n)
+		for i := range keys {
+			keys[i] = rand.Intn(n + 1)
+		}
+		for i := range values {
+			values[i] = rand.Intn(n + 1)
+		}
+		slices.Sort(keys)
+		slices.Sort(values)
+		k, v := slices.Values(keys), slices.Values(values)
+		expected, actual := SortedIntersect(k, v), SortedIntersect(k, values)
+		if !slices.Equal(slices.Collect(actual), slices.Collect(expected)) {
+			t.Errorf("should intersect with %d values", n)
+		}
+		expected, actual = SortedDifference(k, v), SortedDifference(k, values)
+		if !slices.Equal(slices.Collect(actual), slices.Collect(expected)) {
+			t.Errorf("should differ with %d values", n)
+		}
+	}
+}
+
+func TestCheckSorted(t *testing.T) {
+	defer func() {
+		var err *UnsortedError[string]
+		if !errors.As(recover().(error), &err) || err.Prev != "c" || err.Next != "b" {
+			t.Errorf("should report unsorted pair: %v", err)
+		}
+	}()
+	s := CheckSorted(slices.Values([]string{"a", "c", "b"}))
+	for range SortedIntersect(slices.Values([]string{"a", "d"}), s) {
+	}
+	t.Error("should panic")
+}
+
+func TestHashJoin(t *testing.T) {
+	left, right := slices.All([]string{"a", "b"}), slices.All([]int{1, 2, 3})
+	for _, mode := range []JoinMode{InnerJoin, LeftJoin, RightJoin, FullJoin} {
+		for range HashJoin(left, right, mode) {
+			break
+		}
+	}
+	for range HashJoin(right, slices.All([]string{}), LeftJoin) {
+		break
+	}
+	if Size(HashJoin(right, left, RightJoin)) != 2 {
+		t.Error("should be matched")
+	}
+	records := slices.Collect(HashJoin(right, left, LeftJoin))
+	if len(records) != 3 || records[2].HasRight {
+		t.Errorf("should be unmatched: %v", records)
+	}
+	l, r := maps.All(map[int]string{0: "a", 1: "b"}), maps.All(map[int]int{1: 1, 2: 2})
+	counts := [4]int{}
+	for mode := range counts {
+		counts[mode] = Size(HashJoin(l, r, JoinMode(mode)))
+	}
+	if counts != [4]int{1, 2, 2, 3} {
+		t.Errorf("should count records: %v", counts)
+	}
+	for range HashJoin(slices.All([]int{}), right, FullJoin) {
+		break
+	}
+	for _, mode := range []JoinMode{InnerJoin, LeftJoin, RightJoin, FullJoin} {
+		expected := Count(HashJoin(l, r, mode))
+		if built := Count(HashJoin(l, r, mode|BuildLeft)); !maps.Equal(built, expected) {
+			t.Errorf("should build left: %v", built)
+		}
+		for range HashJoin(left, right, mode|BuildLeft) {
+			break
+		}
+	}
+	for range SemiJoin(left, right) {
+		break
+	}
+	for range SemiJoin(left, slices.All([]int{})) {
+		t.Error("should be empty")
+	}
+	for range AntiJoin(right, left) {
+		break
+	}
+}
+
+func TestSortedJoin(t *testing.T) {
+	random := func() []int {
+		keys := make([]int, rand.Intn(10))
+		for i := range keys {
+			keys[i] = rand.Intn(5)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	swap := func(seq iter.Seq2[int, int]) iter.Seq2[int, int] {
+		return func(yield func(int, int) bool) {
+			seq(func(i, key int) bool { return yield(key, i) })
+		}
+	}
+	for range 100 {
+		left, right := swap(slices.All(random())), swap(slices.All(random()))
+		for _, mode := range []JoinMode{InnerJoin, LeftJoin, RightJoin, FullJoin} {
+			expected := Count(HashJoin(left, right, mode))
+			if actual := Count(SortedJoin(left, right, mode)); !maps.Equal(actual, expected) {
+				t.Fatalf("should join: %v != %v", actual, expected)
+			}
+			if actual := Count(SortedJoin(left, right, mode|BuildLeft)); !maps.Equal(actual, expected) {
+				t.Fatalf("should ignore build side: %v != %v", actual, expected)
+			}
+		}
+	}
+	left, right := swap(slices.All([]int{0, 1})), swap(slices.All([]int{1, 2}))
+	for _, mode := range []JoinMode{InnerJoin, LeftJoin, RightJoin, FullJoin} {
+		for range SortedJoin(left, right, mode) {
+			break
+		}
+		for range SortedJoin(right, left, mode) {
+			break
+		}
+	}
+}
+
+func TestAlign(t *testing.T) {
+	random := func() map[int]int {
+		m := map[int]int{}
+		for range rand.Intn(10) {
+			m[rand.Intn(10)] = 0
+		}
+		return m
+	}
+	swap := func(seq iter.Seq[Joined[int, int, int]]) iter.Seq[Joined[int, int, int]] {
+		return func(yield func(Joined[int, int, int]) bool) {
+			for r := range seq {
+				r.Left, r.Right, r.HasLeft, r.HasRight = r.Right, r.Left, r.HasRight, r.HasLeft
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+	for range 100 {
+		m, n := random(), random()
+		expected := Count(HashJoin(maps.All(m), maps.All(n), FullJoin))
+		if actual := Count(Align(m, n)); !maps.Equal(actual, expected) {
+			t.Fatalf("should align: %v != %v", actual, expected)
+		}
+		if actual := Count(swap(Align(n, m))); !maps.Equal(actual, expected) {
+			t.Fatalf("should align: %v != %v", actual, expected)
+		}
+		if actual := Count(swap(AlignSeq(n, maps.All(m)))); !maps.Equal(actual, expected) {
+			t.Fatalf("should align: %v != %v", actual, expected)
+		}
+	}
+	m, n := map[int]int{0: 0, 1: 1}, map[int]int{1: 1, 2: 2, 3: 3}
+	for _, seq := range []iter.Seq[Joined[int, int, int]]{
+		Align(m, n), Align(n, m), AlignSeq(m, maps.All(n)), AlignSeq(n, maps.All(m)),
+	} {
+		for range seq {
+			break
+		}
+		count := Size(seq)
+		for range seq {
+			if count -= 1; count == 0 {
+				break
+			}
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	var c Counter[int]
+	if c.Sum() == nil || c.Union() == nil || len(c.MostCommon(1)) > 0 {
+		t.Error("should be empty")
+	}
+	c = Counter[int](Count(slices.Values([]int{0, 1, 1, 2, 2, 2})))
+	if keys := c.MostCommon(0); len(keys) > 0 {
+		t.Errorf("should be empty: %v", keys)
+	}
+	for range 10 { // map order is random
+		if keys := c.MostCommon(1); !slices.Equal(keys, []int{2}) {
+			t.Errorf("should be most common: %v", keys)
+		}
+	}
+	if keys := c.MostCommon(-1); !slices.Equal(keys, []int{2, 1, 0}) {
+		t.Errorf("should be most common: %v", keys)
+	}
+	for range c.Elements() {
+		break
+	}
+	counts := map[int]int{0: -1, 1: 3, 3: 1}
+	if m := c.Intersect(counts); !maps.Equal(m, Counter[int]{1: 2}) {
+		t.Errorf("should intersect: %v", m)
+	}
+	if m := c.Difference(counts); !maps.Equal(m, Counter[int]{0: 1, 2: 3}) {
+		t.Errorf("should be difference: %v", m)
+	}
+	c.AddCounts(counts)
+	if !maps.Equal(c, Counter[int]{0: 1, 1: 5, 2: 3, 3: 1}) {
+		t.Errorf("should add: %v", c)
+	}
+	c.SubtractCounts(counts)
+	c.Add(slices.Values([]int{0}))
+	if !maps.Equal(c, Counter[int]{0: 2, 1: 2, 2: 3}) {
+		t.Errorf("should subtract: %v", c)
+	}
+}
+
+func TestOrderedMapSet(t *testing.T) {
+	var s OrderedSet[int]
+	if _, ok := s.Get(0); ok || s.Len() != 0 || !s.IsDisjoint(slices.Values([]int{0})) {
+		t.Error("should be empty")
+	}
+	s.Keep(slices.Values([]int{0}))
+	s.Insert(slices.Values([]int{0, 1, 2, 3, 4}), struct{}{})
+	keys := slices.Values([]int{1, 3, 5})
+	if s.IsSuperset(keys) || s.Equal(keys) || s.IsSubset(keys) || s.IsDisjoint(keys) {
+		t.Error("should overlap")
+	}
+	if count := s.IntersectCount(keys); count != 2 {
+		t.Errorf("should intersect: %d", count)
+	}
+	if left, both, right := s.Overlap(keys); left != 3 || both != 2 || right != 1 {
+		t.Errorf("should overlap: %d %d %d", left, both, right)
+	}
+	if actual := slices.Collect(Keys(s.Intersect(keys))); !slices.Equal(actual, []int{1, 3}) {
+		t.Errorf("should intersect: %v", actual)
+	}
+	if actual := slices.Collect(s.ReverseDifference(keys)); !slices.Equal(actual, []int{5}) {
+		t.Errorf("should be reverse difference: %v", actual)
+	}
+	u := s.Union(maps.All(map[int]struct{}{5: {}}))
+	if actual := slices.Collect(u.Keys()); !slices.Equal(actual, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("should be union: %v", actual)
+	}
+	for range s.Intersect(keys) {
+		break
+	}
+	for range s.Difference(keys) {
+		break
+	}
+	for range s.ReverseDifference(keys) {
+		break
+	}
+	for range s.SymmetricDifference(keys) {
+		break
+	}
+	for range s.Backward() {
+		break
+	}
+	for key := range s.All() {
+		s.Delete(key)
+	}
+	if s.Len() != 0 || len(s.entries) > 0 {
+		t.Errorf("should be compacted: %v", s.entries)
+	}
+	s.Toggle(slices.Values([]int{0, 1, 2, 1}), struct{}{})
+	for key := range s.Backward() {
+		s.Remove(slices.Values([]int{key, 0}))
+	}
+	if s.Len() != 0 {
+		t.Errorf("should be empty: %v", s.entries)
+	}
+	s.Add(0, 1, 2, 3)
+	s.Delete(0, 1, 2, 4)
+	if actual := slices.Collect(s.Keys()); !slices.Equal(actual, []int{3}) || len(s.entries) != 1 {
+		t.Errorf("should be compacted: %v", s.entries)
+	}
+	for range s.SymmetricDifference(slices.Values([]int{})) {
+		break
+	}
+	s.Add(0, 1, 2)
+	s.Keep(slices.Values([]int{3, 1, 1}))
+	if actual := slices.Collect(s.Keys()); !slices.Equal(actual, []int{3, 1}) || s.Missing(1) {
+		t.Errorf("should keep: %v", actual)
+	}
+	if !s.Equal(s.Keys()) || !s.IsSubset(s.Keys()) || !s.IsSuperset(s.Keys()) {
+		t.Error("should be equal")
+	}
+}
+
+func checkNode[K cmp.Ordered, V any](t *testing.T, n *node[K, V], root bool) int {
+	t.Helper()
+	if n == nil {
+		return 0
+	}
+	if !slices.IsSorted(n.keys) || len(n.keys) != len(n.values) || len(n.keys) >= 2*degree {
+		t.Fatalf("invalid node: %v", n.keys)
+	}
+	if !root && len(n.keys) < degree-1 {
+		t.Fatalf("underfull node: %v", n.keys)
+	}
+	size := len(n.keys)
+	if !n.leaf() {
+		if len(n.children) != len(n.keys)+1 {
+			t.Fatalf("invalid children: %v", n.keys)
+		}
+		for _, child := range n.children {
+			size += checkNode(t, child, false)
+		}
+	}
+	if size != n.size {
+		t.Fatalf("invalid size: %d != %d", n.size, size)
+	}
+	return size
+}
+
+func TestSortedMap(t *testing.T) {
+	var s SortedMap[int, int]
+	if _, ok := s.Get(0); ok || s.Len() != 0 || s.Rank(0) != 0 {
+		t.Error("should be empty")
+	}
+	if _, _, ok := s.Min(); ok {
+		t.Error("should be empty")
+	}
+	if _, _, ok := s.Floor(0); ok {
+		t.Error("should be empty")
+	}
+	for range s.Range(0, 1) {
+	}
+	for range s.Backward() {
+	}
+	m, r := map[int]int{}, rand.New(rand.NewSource(0))
+	for i := range 50_000 {
+		key := r.Intn(10_000)
+		if r.Intn(3) > 0 {
+			s.Store(key, i)
+			m[key] = i
+		} else {
+			s.Delete(key)
+			delete(m, key)
+		}
+		if i%5_000 == 0 {
+			checkNode(t, s.root, true)
+		}
+	}
+	checkNode(t, s.root, true)
+	keys := slices.Sorted(maps.Keys(m))
+	if actual := slices.Collect(s.Keys()); !slices.Equal(actual, keys) {
+		t.Fatalf("should be sorted: %v", actual)
+	}
+	backward := slices.Collect(Keys(s.Backward()))
+	slices.Reverse(backward)
+	if !slices.Equal(backward, keys) {
+		t.Fatalf("should be reversed: %v", backward)
+	}
+	for range 100 {
+		key, hi := r.Intn(10_100)-50, r.Intn(10_100)-50
+		i, found := slices.BinarySearch(keys, key)
+		j, _ := slices.BinarySearch(keys, hi)
+		if value, ok := s.Get(key); ok != found || value != m[key] || s.Contains(key) != found {
+			t.Fatalf("should get: %d", key)
+		}
+		if rank := s.Rank(key); rank != i {
+			t.Fatalf("should rank: %d != %d", rank, i)
+		}
+		if k, v, ok := s.Select(i); ok != (i < len(keys)) || (ok && (k != keys[i] || v != m[k])) {
+			t.Fatalf("should select: %d", i)
+		}
+		if k, _, ok := s.Ceiling(key); ok != (i < len(keys)) || (ok && k != keys[i]) {
+			t.Fatalf("should be ceiling: %d", key)
+		}
+		actual, expected := slices.Collect(Keys(s.Range(key, hi))), keys[i:max(i, j)]
+		if len(actual) != len(expected) || !slices.Equal(actual, expected) {
+			t.Fatalf("should be range: %v != %v", actual, expected)
+		}
+		if found {
+			i += 1
+		}
+		if k, _, ok := s.Floor(key); ok != (i > 0) || (ok && k != keys[i-1]) {
+			t.Fatalf("should be floor: %d", key)
+		}
+	}
+	for range s.Range(0, 10_000) {
+		break
+	}
+	for i := range s.Backward() {
+		if i < keys[len(keys)-100] {
+			break
+		}
+	}
+	if k, _, _ := s.Max(); k != keys[len(keys)-1] {
+		t.Errorf("should be max: %d", k)
+	}
+	s.Keep(slices.Values(keys[:100]))
+	s.Keep(slices.Values(keys))
+	checkNode(t, s.root, true)
+	if s.Len() != 100 || !s.IsSuperset(slices.Values(keys[:100])) || s.Missing(keys[0]) {
+		t.Errorf("should keep: %d", s.Len())
+	}
+	s.Remove(slices.Values(keys))
+	if s.Len() != 0 || s.root != nil {
+		t.Errorf("should be empty: %d", s.Len())
+	}
+}
+
+func TestBitSet(t *testing.T) {
+	var b BitSet
+	if b.Contains(-1) || !b.Missing(0) || b.Len() != 0 {
+		t.Error("should be empty")
+	}
+	b.Delete(-1, 1_000)
+	b.Toggle(slices.Values([]int{1, 200, 1}))
+	if !b.Equal(NewBitSet(slices.Values([]int{200}))) {
+		t.Errorf("should toggle: %v", b.words)
+	}
+	for range 100 {
+		m1, m2 := Set[int](), Set[int]()
+		for range rand.Intn(20) {
+			m1.add(rand.Intn(200))
+			m2.add(rand.Intn(200))
+		}
+		b1, b2 := NewBitSet(maps.Keys(m1)), NewBitSet(maps.Keys(m2))
+		if !maps.Equal(b1.MapSet(), m1) || b1.Len() != len(m1) {
+			t.Fatalf("should convert: %v", b1.MapSet())
+		}
+		for _, b := range []*BitSet{b1.Clone(), b1} {
+			if !b.Equal(b1) {
+				t.Fatalf("should be equal: %v", b.words)
+			}
+		}
+		if b1.IsSubset(b2) != m1.IsSubset(maps.Keys(m2)) {
+			t.Fatalf("should be subset: %v", b1.words)
+		}
+		if b1.IsDisjoint(b2) != m1.IsDisjoint(maps.Keys(m2)) {
+			t.Fatalf("should be disjoint: %v", b1.words)
+		}
+		if b1.IntersectCount(b2) != m1.IntersectCount(maps.Keys(m2)) {
+			t.Fatalf("should intersect: %v", b1.words)
+		}
+		expected := m1.Union(maps.All(m2))
+		if actual := b1.Union(b2).MapSet(); !maps.Equal(actual, expected) {
+			t.Fatalf("should be union: %v", actual)
+		}
+		expected = Collect(Keys(m1.Intersect(maps.Keys(m2))), struct{}{})
+		if actual := b1.Intersect(b2).MapSet(); !maps.Equal(actual, expected) {
+			t.Fatalf("should intersect: %v", actual)
+		}
+		expected = Collect(Keys(m1.Difference(maps.Keys(m2))), struct{}{})
+		if actual := b1.Difference(b2).MapSet(); !maps.Equal(actual, expected) {
+			t.Fatalf("should be difference: %v", actual)
+		}
+		expected = Collect(m1.SymmetricDifference(maps.Keys(m2)), struct{}{})
+		if actual := b1.SymmetricDifference(b2).MapSet(); !maps.Equal(actual, expected) {
+			t.Fatalf("should be symmetric difference: %v", actual)
+		}
+		b1.Remove(maps.Keys(m1))
+		for range b2.All() {
+			break
+		}
+		if b1.Len() != 0 || !b1.Equal(&BitSet{}) {
+			t.Fatalf("should be empty: %v", b1.words)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("should panic")
+		}
+	}()
+	b.Add(-1)
+}
+
+func TestBitmap(t *testing.T) {
+	var b Bitmap
+	if _, ok := b.Select(0); ok || b.Contains(0) || !b.Missing(0) || b.Rank(1) != 0 {
+		t.Error("should be empty")
+	}
+	random := func(r *rand.Rand) []uint32 {
+		keys := []uint32{}
+		for range r.Intn(3) { // sparse
+			for range r.Intn(100) {
+				keys = append(keys, uint32(r.Intn(1<<18)))
+			}
+		}
+		for range r.Intn(3) { // dense
+			high := uint32(r.Intn(4)) << 16
+			for range r.Intn(10_000) {
+				keys = append(keys, high|uint32(r.Intn(1<<16)))
+			}
+		}
+		for range r.Intn(3) { // runs
+			start := uint32(r.Intn(1 << 18))
+			for i := range uint32(r.Intn(10_000)) {
+				keys = append(keys, start+i)
+			}
+		}
+		slices.Sort(keys)
+		return slices.Compact(keys)
+	}
+	r := rand.New(rand.NewSource(0))
+	for range 20 {
+		k1, k2 := random(r), random(r)
+		b1, b2 := NewBitmap(slices.Values(k1)), NewBitmap(slices.Values(k2))
+		if r.Intn(2) == 0 {
+			b1.Optimize()
+		}
+		if r.Intn(2) == 0 {
+			b2.Optimize()
+		}
+		if actual := slices.Collect(b1.All()); !slices.Equal(actual, k1) || b1.Len() != len(k1) {
+			t.Fatalf("should iterate: %d != %d", len(actual), len(k1))
+		}
+		diff := SortedDifference(slices.Values(k2), k1)
+		expected := slices.Collect(SortedUnion(slices.Values(k1), diff))
+		if actual := slices.Collect(b1.Union(b2).All()); !slices.Equal(actual, expected) {
+			t.Fatalf("should be union: %d != %d", len(actual), len(expected))
+		}
+		expected = slices.Collect(SortedIntersect(slices.Values(k1), k2))
+		if actual := slices.Collect(b1.Intersect(b2).All()); !slices.Equal(actual, expected) {
+			t.Fatalf("should intersect: %d != %d", len(actual), len(expected))
+		}
+		for range 10 {
+			key := uint32(r.Intn(1 << 18))
+			i, found := slices.BinarySearch(k1, key)
+			if b1.Contains(key) != found || b1.Rank(key) != i {
+				t.Fatalf("should rank: %d", key)
+			}
+			if len(k1) == 0 {
+				continue
+			}
+			i = r.Intn(len(k1))
+			if key, ok := b1.Select(i); !ok || key != k1[i] {
+				t.Fatalf("should select: %d != %d", key, k1[i])
+			}
+		}
+		data, err := b1.MarshalBinary()
+		var c Bitmap
+		if err != nil || c.UnmarshalBinary(data) != nil || !slices.Equal(slices.Collect(c.All()), k1) {
+			t.Fatalf("should round trip: %v", err)
+		}
+		if len(data) > 4 && c.UnmarshalBinary(data[:len(data)-1]) == nil {
+			t.Fatal("should be invalid")
+		}
+		c.Remove(slices.Values(k1[:len(k1)/2]))
+		c.Delete(k1[len(k1)/2:]...)
+		c.Delete(0)
+		if c.Len() != 0 || len(c.containers) > 0 {
+			t.Fatalf("should be empty: %d", c.Len())
+		}
+		for range b1.All() {
+			break
+		}
+	}
+}
+
+func TestBitmapEdges(t *testing.T) {
+	var b Bitmap
+	b.Add(1, 1, 2)
+	b.Insert(func(yield func(uint32) bool) {
+		for i := range uint32(arrayMax + 1) {
+			_ = yield(i<<4) && yield(i<<4)
+		}
+	})
+	b.Delete(3, 1<<30)
+	if b.Len() != arrayMax+3 || b.containers[0].kind != bitmapKind {
+		t.Errorf("should be bitmap: %d", b.Len())
+	}
+	c := b.Clone()
+	c.Delete(1, 16, 32)
+	if !b.Contains(1) || c.Contains(1) || c.containers[0].kind != arrayKind {
+		t.Error("should be cloned")
+	}
+	if _, ok := b.Select(-1); ok {
+		t.Error("should be out of range")
+	}
+	header := func(key uint16, kind uint8, size int) []byte {
+		data := binary.LittleEndian.AppendUint16(nil, key)
+		return binary.LittleEndian.AppendUint32(append(data, kind), uint32(size))
+	}
+	array := binary.LittleEndian.AppendUint16(header(0, arrayKind, 1), 5)
+	invalid := [][]byte{
+		{1, 0},
+		{1, 0, 0, 0},
+		slices.Concat([]byte{2, 0, 0, 0}, array, array),
+		slices.Concat([]byte{1, 0, 0, 0}, header(0, arrayKind, 2), []byte{5, 0, 3, 0}),
+		slices.Concat([]byte{1, 0, 0, 0}, header(0, runKind, 1), []byte{5, 0, 3, 0}),
+		slices.Concat([]byte{1, 0, 0, 0}, header(0, runKind, 2), []byte{1, 0, 3, 0, 4, 0, 5, 0}),
+		slices.Concat([]byte{1, 0, 0, 0}, header(0, bitmapKind, 1024), make([]byte, 8192)),
+		slices.Concat([]byte{1, 0, 0, 0}, header(0, 3, 0)),
+		slices.Concat([]byte{1, 0, 0, 0}, array, []byte{0}),
+	}
+	for _, data := range invalid {
+		if err := b.UnmarshalBinary(data); err != ErrEncoding {
+			t.Errorf("should be invalid: %v", data)
+		}
+	}
+	if b.Len() != arrayMax+3 {
+		t.Errorf("should be unchanged: %d", b.Len())
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	hash := func(key int) uint64 { return uint64(key) * 0x9e3779b97f4a7c15 }
+	f1, f2 := NewBloomFilter(1000, 0.01, hash), NewBloomFilter(1000, 0.01, hash)
+	f1.Add(slices.Collect(Keys(slices.All(make([]int, 1000))))...)
+	f2.Insert(func(yield func(int) bool) {
+		for i := 500; i < 1500 && yield(i); i++ {
+		}
+	})
+	union, err := f1.Union(f2)
+	if err != nil || !union.Contains(0) || !union.Contains(1499) {
+		t.Errorf("should be union: %v", err)
+	}
+	inter, err := f1.Intersect(f2)
+	if err != nil || !inter.Contains(500) || !inter.Contains(999) {
+		t.Errorf("should intersect: %v", err)
+	}
+	positives := 0
+	for i := 2000; i < 12_000; i++ {
+		if f1.Contains(i) {
+			positives += 1
+		}
+	}
+	if positives > 200 {
+		t.Errorf("should have a low false positive rate: %d", positives)
+	}
+	if _, err := f1.Union(NewBloomFilter(10, 0.01, hash)); err != ErrMismatch {
+		t.Errorf("should mismatch: %v", err)
+	}
+	data, _ := f1.MarshalBinary()
+	f, err := UnmarshalBloomFilter(data, hash)
+	if err != nil {
+		t.Fatalf("should round trip: %v", err)
+	}
+	for i := range 1000 {
+		if !f.Contains(i) {
+			t.Fatalf("should not be a false negative: %d", i)
+		}
+	}
+	f = NewBloomFilter(1, 0.5, hash)
+	if err := f.UnmarshalBinary(data); err != nil || !f.Contains(999) {
+		t.Errorf("should round trip: %v", err)
+	}
+	for _, data := range [][]byte{data[:7], data[:len(data)-1], make([]byte, 8)} {
+		if _, err := UnmarshalBloomFilter(data, hash); err != ErrEncoding {
+			t.Errorf("should be invalid: %v", err)
+		}
+	}
+	for _, p := range []float64{0, 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("should panic: %v", p)
+				}
+			}()
+			NewBloomFilter[int](1, p, nil)
+		}()
+	}
+}
+
+func TestCuckooFilter(t *testing.T) {
+	f := NewCuckooFilter[int](10_000, nil)
+	n := 0
+	for ; f.Add(n) == nil; n++ {
+	}
+	if f.LoadFactor() < 0.9 || f.Len() != n {
+		t.Errorf("should be full: %v", f.LoadFactor())
+	}
+	if err := f.Insert(slices.Values([]int{n})); err != ErrFull {
+		t.Errorf("should be full: %v", err)
+	}
+	keys := slices.Collect(Keys(slices.All(make([]int, n))))
+	if keys := slices.DeleteFunc(keys, f.Contains); len(keys) > 0 {
+		t.Fatalf("should not have false negatives: %v", keys)
+	}
+	positives := 0
+	for i := n; i < n+100_000; i++ {
+		if f.Contains(i) {
+			positives += 1
+		}
+	}
+	if rate := float64(positives) / 100_000; rate > 0.001 {
+		t.Errorf("should have a low false positive rate: %v", rate)
+	}
+	f.Remove(Keys(slices.All(make([]int, n/10))))
+	if f.victim.ok || f.Len() != n-n/10 || f.Add(n) != nil {
+		t.Errorf("should not be full: %v", f.victim)
+	}
+	f.Delete(n)
+	f.Remove(Keys(slices.All(make([]int, n))))
+	if f.Len() != 0 || f.LoadFactor() != 0 || !f.Missing(1) {
+		t.Errorf("should be empty: %d", f.Len())
+	}
+	hash := func(key int) uint64 { return uint64(key) << 48 } // fingerprints are keys
+	f = NewCuckooFilter(1, hash)
+	if err := f.Add(1, 2, 3, 4, 5); err != nil || !f.victim.ok {
+		t.Fatalf("should have a victim: %v", err)
+	}
+	if f.Add(6) != ErrFull || f.Missing(int(f.victim.fp)) {
+		t.Fatal("should be full")
+	}
+	f.Delete(int(f.victim.fp))
+	if f.victim.ok || f.Len() != 4 {
+		t.Errorf("should delete victim: %v", f.victim)
+	}
+}
+
+func splitmix(key int) uint64 {
+	x := uint64(key) + 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+func TestHyperLogLog(t *testing.T) {
+	for _, precision := range []int{4, 5, 6, 10, 14} {
+		h := NewHyperLogLog[int](precision, nil)
+		if h.Len() != 0 {
+			t.Errorf("should be empty: %d", h.Len())
+		}
+		n := 100_000
+		h.Insert(Keys(slices.All(make([]int, n))))
+		tolerance := 4 * 1.04 / math.Sqrt(float64(int(1)<<precision))
+		if estimate := h.Len(); math.Abs(float64(estimate-n))/float64(n) > tolerance {
+			t.Errorf("should estimate: %d with precision %d", estimate, precision)
+		}
+	}
+	h1, h2 := NewHyperLogLog(12, splitmix), NewHyperLogLog(12, splitmix)
+	h1.Insert(Keys(slices.All(make([]int, 20_000))))
+	h2.Insert(func(yield func(int) bool) {
+		for i := 10_000; i < 40_000 && yield(i); i++ {
+		}
+	})
+	left, both, right, err := h1.Overlap(h2)
+	if err != nil || math.Abs(float64(both-10_000)) > 2_000 || left+both != h1.Len() {
+		t.Errorf("should overlap: %d %d %d", left, both, right)
+	}
+	if err := h1.Merge(h2); err != nil || math.Abs(float64(h1.Len()-40_000)) > 2_000 {
+		t.Errorf("should merge: %d", h1.Len())
+	}
+	if _, _, _, err := h1.Overlap(NewHyperLogLog(4, splitmix)); err != ErrMismatch {
+		t.Errorf("should mismatch: %v", err)
+	}
+	for _, precision := range []int{3, 19} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("should panic: %d", precision)
+				}
+			}()
+			NewHyperLogLog(precision, splitmix)
+		}()
+	}
+}
+
+func TestCountMinSketch(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	keys := make([]int, 100_000)
+	for i := range keys {
+		keys[i] = int(r.ExpFloat64() * 1000) // skewed towards small keys
+	}
+	counts := Count(slices.Values(keys))
+	errs := [2]int{}
+	for i, conservative := range []bool{false, true} {
+		s := NewCountMinSketch(0.001, 0.01, conservative, splitmix)
+		s.Insert(slices.Values(keys))
+		for key, count := range counts {
+			estimate := s.Estimate(key)
+			if estimate < count || estimate-count > len(keys)/1000 {
+				t.Errorf("should estimate: %d %d", estimate, count)
+			}
+			errs[i] += estimate - count
+		}
+	}
+	if errs[1] >= errs[0] {
+		t.Errorf("conservative should reduce error: %v", errs)
+	}
+	s1 := NewCountMinSketch[int](0.1, 0.1, false, nil)
+	s2 := NewCountMinSketch[int](0.1, 0.1, false, nil)
+	s1.Add(1, 2)
+	s2.Add(1, 3)
+	if err := s1.Merge(s2); err != nil || s1.Estimate(1) != 5 {
+		t.Errorf("should merge: %d", s1.Estimate(1))
+	}
+	for range s1.indexes(1) {
+		break
+	}
+	if err := s1.Merge(NewCountMinSketch[int](0.01, 0.1, false, nil)); err != ErrMismatch {
+		t.Errorf("should mismatch: %v", err)
+	}
+	for _, params := range [][2]float64{{0, 0.1}, {0.1, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("should panic: %v", params)
+				}
+			}()
+			NewCountMinSketch[int](params[0], params[1], false, nil)
+		}()
+	}
+}
+
+func TestTopK(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	keys := make([]int, 100_000)
+	for i := range keys {
+		keys[i] = int(r.ExpFloat64() * 100)
+	}
+	top := NewTopK(5, NewCountMinSketch(0.001, 0.01, true, splitmix))
+	top.Insert(slices.Values(keys))
+	counts := Count(slices.Values(keys))
+	expected := Counter[int](counts).MostCommon(5)
+	if got := slices.Collect(Keys(top.All())); !slices.Equal(got, expected) {
+		t.Errorf("should find heavy hitters: %v %v", got, expected)
+	}
+	for key, count := range top.All() {
+		if count < counts[key] {
+			t.Errorf("should not underestimate: %d %d", count, counts[key])
+		}
+		break
+	}
+	empty := NewTopK(0, NewCountMinSketch[int](0.1, 0.1, false, nil))
+	empty.Add(1, 1)
+	if Size(Keys(empty.All())) != 0 {
+		t.Error("should be empty")
+	}
+}
+
+func TestSpaceSaving(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	zipf := rand.NewZipf(r, 1.2, 1, 10_000)
+	keys := make([]int, 100_000)
+	for i := range keys {
+		keys[i] = int(zipf.Uint64())
+	}
+	check := func(s *SpaceSaving[int], keys []int) {
+		counts := Count(slices.Values(keys))
+		if s.Total() != len(keys) || s.Len() != 50 {
+			t.Errorf("should count: %d %d", s.Total(), s.Len())
+		}
+		for key, actual := range counts {
+			count, err := s.Estimate(key)
+			if count < actual || count-err > actual || err > len(keys)/50 {
+				t.Errorf("should bound: %d %d %d", count, err, actual)
+			}
+			if _, ok := s.index[key]; !ok && actual > len(keys)/50 {
+				t.Errorf("should track: %d %d", key, actual)
+			}
+		}
+		expected := Counter[int](counts).MostCommon(5)
+		if got := slices.Collect(Keys(s.All()))[:5]; !slices.Equal(got, expected) {
+			t.Errorf("should find heavy hitters: %v %v", got, expected)
+		}
+	}
+	s1, s2 := NewSpaceSaving[int](50), NewSpaceSaving[int](50)
+	s1.Insert(slices.Values(keys[:60_000]))
+	s2.Insert(slices.Values(keys[60_000:]))
+	check(s1, keys[:60_000])
+	s1.Merge(s2)
+	check(s1, keys)
+	for range s1.All() {
+		break
+	}
+	s3 := NewSpaceSaving[int](50)
+	s3.Add(0, 1, 1)
+	s1.Merge(s3)
+	if count, err := s1.Estimate(1); count < s3.heap.values[s3.index[1]].count || err < 0 {
+		t.Errorf("should merge: %d %d", count, err)
+	}
+	s3.Merge(s1)
+	if s3.Len() != 50 || s3.Total() != len(keys)+6 {
+		t.Errorf("should merge: %d %d", s3.Len(), s3.Total())
+	}
+	if count, err := s3.Estimate(-1); count == 0 || count != err {
+		t.Errorf("should bound untracked: %d %d", count, err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("should panic")
+		}
+	}()
+	NewSpaceSaving[int](0)
 }
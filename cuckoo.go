@@ -0,0 +1,184 @@
+package iterset
+
+import (
+	"errors"
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// ErrFull is returned when a key can not be added to a full filter.
+var ErrFull = errors.New("iterset: filter is full")
+
+const maxKicks = 500
+
+// CuckooFilter is a probabilistic set which may report false positives, but not false negatives,
+// and which supports deletion. It stores 16-bit fingerprints in buckets of 4,
+// with partial-key cuckoo hashing between 2 buckets per key.
+// The false positive rate is about 8 / 65536.
+//
+// Keys are relocated when their buckets are full, and the last relocated fingerprint is kept
+// in a victim slot if relocation fails. Then the filter is full until a key is deleted.
+type CuckooFilter[K comparable] struct {
+	buckets [][4]uint16
+	count   int
+	hash    func(K) uint64
+	victim  struct {
+		fp    uint16
+		index uint64
+		ok    bool
+	}
+}
+
+// NewCuckooFilter returns an empty [CuckooFilter] with capacity for at least n keys.
+// If hash is nil, [maphash.Comparable] is used.
+func NewCuckooFilter[K comparable](n int, hash func(K) uint64) *CuckooFilter[K] {
+	size := uint64(max(n, 1)+3) / 4 * 100 / 95
+	return &CuckooFilter[K]{buckets: make([][4]uint16, 1<<bits.Len64(size-1)), hash: hash}
+}
+
+// fingerprint returns the non-zero fingerprint and primary bucket of the key.
+func (f *CuckooFilter[K]) fingerprint(key K) (uint16, uint64) {
+	var h uint64
+	if f.hash == nil {
+		h = maphash.Comparable(seed, key)
+	} else {
+		h = f.hash(key)
+	}
+	return max(uint16(h>>48), 1), h & uint64(len(f.buckets)-1)
+}
+
+// alt returns the alternate bucket of a fingerprint, which is symmetric.
+func (f *CuckooFilter[K]) alt(index uint64, fp uint16) uint64 {
+	return (index ^ uint64(fp)*0x5bd1e995) & uint64(len(f.buckets)-1)
+}
+
+func (f *CuckooFilter[K]) insert(index uint64, fp uint16) bool {
+	for i, value := range f.buckets[index] {
+		if value == 0 {
+			f.buckets[index][i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (f *CuckooFilter[K]) delete(index uint64, fp uint16) bool {
+	for i, value := range f.buckets[index] {
+		if value == fp {
+			f.buckets[index][i] = 0
+			return true
+		}
+	}
+	return false
+}
+
+func (f *CuckooFilter[K]) add(key K) error {
+	if f.victim.ok {
+		return ErrFull
+	}
+	fp, index := f.fingerprint(key)
+	f.count += 1
+	f.place(fp, index)
+	return nil
+}
+
+// place inserts the fingerprint, relocating others if necessary, or else sets the victim.
+func (f *CuckooFilter[K]) place(fp uint16, index uint64) {
+	if f.insert(index, fp) || f.insert(f.alt(index, fp), fp) {
+		return
+	}
+	if rand.IntN(2) == 0 {
+		index = f.alt(index, fp)
+	}
+	for range maxKicks {
+		i := rand.IntN(4)
+		fp, f.buckets[index][i] = f.buckets[index][i], fp
+		if index = f.alt(index, fp); f.insert(index, fp) {
+			return
+		}
+	}
+	f.victim.fp, f.victim.index, f.victim.ok = fp, index, true
+}
+
+// Len returns the number of keys added.
+func (f *CuckooFilter[K]) Len() int {
+	return f.count
+}
+
+// LoadFactor returns the fraction of fingerprint slots which are used.
+func (f *CuckooFilter[K]) LoadFactor() float64 {
+	return float64(f.count) / float64(4*len(f.buckets))
+}
+
+// Contains returns whether the key may be present.
+func (f *CuckooFilter[K]) Contains(key K) bool {
+	fp, index := f.fingerprint(key)
+	alt := f.alt(index, fp)
+	for _, b := range [...][4]uint16{f.buckets[index], f.buckets[alt]} {
+		if b[0] == fp || b[1] == fp || b[2] == fp || b[3] == fp {
+			return true
+		}
+	}
+	return f.victim.ok && f.victim.fp == fp && (f.victim.index == index || f.victim.index == alt)
+}
+
+// Missing returns whether the key is definitely not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (f *CuckooFilter[K]) Missing(key K) bool {
+	return !f.Contains(key)
+}
+
+// Add key(s). Returns [ErrFull] if the filter is full, and stops adding keys.
+// Duplicate keys are added again, so they can be deleted the same number of times.
+func (f *CuckooFilter[K]) Add(keys ...K) error {
+	for _, key := range keys {
+		if err := f.add(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert keys. Returns [ErrFull] if the filter is full, and stops consuming keys.
+func (f *CuckooFilter[K]) Insert(keys iter.Seq[K]) error {
+	for key := range keys {
+		if err := f.add(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete key(s). Only keys which were added should be deleted;
+// otherwise a key with the same fingerprint may be deleted instead.
+func (f *CuckooFilter[K]) Delete(keys ...K) {
+	for _, key := range keys {
+		f.remove(key)
+	}
+}
+
+func (f *CuckooFilter[K]) remove(key K) {
+	fp, index := f.fingerprint(key)
+	alt := f.alt(index, fp)
+	switch {
+	case f.delete(index, fp) || f.delete(alt, fp):
+		if f.victim.ok {
+			f.victim.ok = false
+			f.place(f.victim.fp, f.victim.index)
+		}
+	case f.victim.ok && f.victim.fp == fp && (f.victim.index == index || f.victim.index == alt):
+		f.victim.ok = false
+	default:
+		return
+	}
+	f.count -= 1
+}
+
+// Remove keys. Only keys which were added should be removed.
+func (f *CuckooFilter[K]) Remove(keys iter.Seq[K]) {
+	for key := range keys {
+		f.remove(key)
+	}
+}
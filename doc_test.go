@@ -1,11 +1,15 @@
 package iterset
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Update a slice by removing common adapted keys.
@@ -428,16 +432,90 @@ func ExampleSortedUnion() {
 	// Output: [a b b c d]
 }
 
+func ExampleSortedUnionFunc() {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s1 := slices.Values([]time.Time{start, start.Add(2 * time.Hour)})
+	s2 := []time.Time{start.Add(time.Hour)}
+	for t := range SortedUnionFunc(s1, s2, time.Time.Compare) {
+		fmt.Println(t.Hour())
+	}
+	// Output:
+	// 0
+	// 1
+	// 2
+}
+
 func ExampleSortedIntersect() {
 	s1, s2 := slices.Values([]string{"b", "c", "d", "d"}), slices.Values([]string{"a", "b", "d"})
 	fmt.Println(slices.Collect(SortedIntersect(s1, s2)))
-	// Output: [b d]
+	fmt.Println(slices.Collect(SortedIntersect(s1, []string{"b", "d", "d"}, []string{"d", "d"})))
+	// Output:
+	// [b d]
+	// [d d]
+}
+
+func ExampleSortedIntersectFunc() {
+	type user struct {
+		id   int
+		name string
+	}
+	ids := slices.Values([]int{1, 3})
+	users := []user{{1, "a"}, {2, "b"}, {3, "c"}}
+	compare := func(id int, u user) int { return cmp.Compare(id, u.id) }
+	for id, u := range SortedIntersectFunc(ids, users, compare) {
+		fmt.Println(id, u.name)
+	}
+	// Output:
+	// 1 a
+	// 3 c
 }
 
 func ExampleSortedDifference() {
 	s1, s2 := slices.Values([]string{"b", "b", "c"}), slices.Values([]string{"a", "b", "d"})
 	fmt.Println(slices.Collect(SortedDifference(s1, s2)))
-	// Output: [b c]
+	fmt.Println(slices.Collect(SortedDifference(s1, []string{"b"}, []string{"b", "c"})))
+	// Output:
+	// [b c]
+	// []
+}
+
+func ExampleSortedDifferenceFunc() {
+	words := slices.Values([]string{"a", "B", "c"})
+	compare := func(a, b string) int { return strings.Compare(strings.ToLower(a), b) }
+	fmt.Println(slices.Collect(SortedDifferenceFunc(words, []string{"b"}, compare)))
+	// Output: [a c]
+}
+
+func ExampleSortedSymmetricDifference() {
+	s1, s2 := slices.Values([]string{"b", "b", "c"}), []string{"a", "b", "d"}
+	for key, index := range SortedSymmetricDifference(s1, s2) {
+		fmt.Println(key, index)
+	}
+	// Output:
+	// a 1
+	// b 0
+	// c 0
+	// d 1
+}
+
+func ExampleCheckSorted() {
+	defer func() {
+		fmt.Println(recover())
+	}()
+	s1, s2 := slices.Values([]string{"a", "c", "b"}), []string{"b", "c"}
+	for key := range SortedIntersect(CheckSorted(s1), s2) {
+		fmt.Println(key)
+	}
+	// Output:
+	// c
+	// iterset: keys are not sorted: c before b
+}
+
+func ExampleCheckSortedFunc() {
+	s := slices.Values([]string{"a", "B", "c"})
+	compare := func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) }
+	fmt.Println(slices.Collect(CheckSortedFunc(s, compare)))
+	// Output: [a B c]
 }
 
 func ExampleGoIter() {
@@ -445,3 +523,430 @@ func ExampleGoIter() {
 	fmt.Println(slices.Collect(GoIter(context.Background(), s, 0)))
 	// Output: [a b c]
 }
+
+func ExampleNewSyncMapSet() {
+	s := NewSyncMapSet(map[string]int{"a": 0})
+	var wg sync.WaitGroup
+	for _, key := range []string{"b", "c", "b"} {
+		wg.Go(func() { s.Add(key) })
+	}
+	wg.Wait()
+	fmt.Println(s.Clone())
+	// Output: map[a:0 b:0 c:0]
+}
+
+func ExampleSyncMapSet_AddIfMissing() {
+	var s SyncMapSet[string, int]
+	fmt.Println(s.AddIfMissing("a", 1))
+	fmt.Println(s.AddIfMissing("a", 2))
+	// Output:
+	// 1 true
+	// 1 false
+}
+
+func ExampleSyncMapSet_RemoveAll() {
+	s := NewSyncMapSet(Set("a", "b"))
+	fmt.Println(s.RemoveAll(slices.Values([]string{"a", "c"})), s.Len())
+	fmt.Println(s.RemoveAll(slices.Values([]string{"a", "b"})), s.Len())
+	// Output:
+	// false 2
+	// true 0
+}
+
+func ExampleSyncMapSet_Do() {
+	s := NewSyncMapSet(map[string]int{"a": 1})
+	s.Do(func(m MapSet[string, int]) { m["a"] += 1 })
+	fmt.Println(s.Get("a"))
+	// Output: 2 true
+}
+
+func ExampleSyncMapSet_Intersect() {
+	s := NewSyncMapSet(map[string]int{"a": 0, "b": 1})
+	for key, value := range s.Intersect(slices.Values([]string{"b", "c"})) {
+		s.Delete(key) // iteration does not hold the lock
+		fmt.Println(key, value)
+	}
+	fmt.Println(s.Len())
+	// Output:
+	// b 1
+	// 1
+}
+
+func ExampleNewShardedMapSet() {
+	s := NewShardedMapSet[string, int](4)
+	var wg sync.WaitGroup
+	for _, key := range []string{"b", "c", "b"} {
+		wg.Go(func() { s.Add(key) })
+	}
+	wg.Wait()
+	fmt.Println(s.Len(), s.Contains("b"), s.Contains("a"))
+	// Output: 2 true false
+}
+
+func ExampleShardedMapSet_Difference() {
+	s := NewShardedMapSet[string, int](0)
+	s.Insert(slices.Values([]string{"a", "b"}), 1)
+	fmt.Println(maps.Collect(s.Difference(slices.Values([]string{"b", "c"}))))
+	// Output: map[a:1]
+}
+
+func ExampleGoCount() {
+	fmt.Println(GoCount[string]([]string{"b", "a", "b"}, 2))
+	fmt.Println(GoCount[string](slices.Values([]string{"b", "a", "b"}), 0))
+	// Output:
+	// map[a:1 b:2]
+	// map[a:1 b:2]
+}
+
+func ExampleGoIndexBy() {
+	fmt.Println(GoIndexBy([]string{"B", "a", "b"}, strings.ToLower, 2))
+	// Output: map[a:a b:b]
+}
+
+func ExampleGoGroupBy() {
+	fmt.Println(GoGroupBy([]string{"B", "a", "b"}, strings.ToLower, 2))
+	// Output: map[a:[a] b:[B b]]
+}
+
+func ExampleGoReduce() {
+	values := []string{"a", "bb", "c"}
+	concat := func(s1, s2 string) string { return s1 + s2 }
+	fmt.Println(GoReduce(values, func(s string) int { return len(s) }, concat, 2))
+	// Output: map[1:ac 2:bb]
+}
+
+func ExampleGoBatch() {
+	s := slices.Values([]string{"a", "b", "c"})
+	fmt.Println(slices.Collect(GoBatch(context.Background(), s, 0, 2, false)))
+	fmt.Println(slices.Collect(GoBatch(context.Background(), s, 0, 0, true)))
+	// Output:
+	// [a b c]
+	// [a b c]
+}
+
+func ExampleGoMap() {
+	s := slices.Values([]string{"a", "b", "c"})
+	fmt.Println(slices.Collect(GoMap(context.Background(), s, 2, strings.ToUpper)))
+	// Output: [A B C]
+}
+
+func ExampleGoMapUnordered() {
+	s := slices.Values([]string{"a", "b", "c"})
+	fmt.Println(slices.Sorted(GoMapUnordered(context.Background(), s, 2, strings.ToUpper)))
+	// Output: [A B C]
+}
+
+func ExampleGoIter2() {
+	s := slices.All([]string{"a", "b"})
+	for key, value := range GoIter2(context.Background(), s, 0) {
+		fmt.Println(key, value)
+	}
+	// Output:
+	// 0 a
+	// 1 b
+}
+
+func ExampleGoIterErr() {
+	produce := func(yield func(string) bool) error {
+		for _, value := range []string{"a", "b"} {
+			if !yield(value) {
+				return nil
+			}
+		}
+		return errors.New("failed")
+	}
+	s, err := GoIterErr(context.Background(), produce, 0)
+	fmt.Println(slices.Collect(s), err())
+	// Output: [a b] failed
+}
+
+func ExampleSortedMerge() {
+	s1, s2 := slices.Values([]string{"b", "c"}), slices.Values([]string{"a", "b", "d"})
+	fmt.Println(slices.Collect(SortedMerge(s1, s2, slices.Values([]string{"c"}))))
+	// Output: [a b b c c d]
+}
+
+func ExampleSortedMergeUnique() {
+	s1, s2 := slices.Values([]string{"b", "c"}), []string{"a", "b", "d"}
+	fmt.Println(slices.Collect(SortedMergeUnique(s1, s2, []string{"c", "c"})))
+	// Output: [a b c d]
+}
+
+func ExampleHashJoin() {
+	users := func(yield func(int, string) bool) {
+		_ = yield(1, "alice") && yield(2, "bob") && yield(3, "carol")
+	}
+	orders := func(yield func(int, string) bool) {
+		_ = yield(1, "book") && yield(3, "pen") && yield(1, "cup") && yield(4, "ink")
+	}
+	for r := range HashJoin(users, orders, InnerJoin) {
+		fmt.Println(r.Key, r.Left, r.Right)
+	}
+	for r := range HashJoin(users, orders, FullJoin) {
+		fmt.Println(r.Key, r.HasLeft, r.HasRight)
+	}
+	for r := range HashJoin(users, orders, InnerJoin|BuildLeft) {
+		fmt.Println(r.Key, r.Left, r.Right)
+	}
+	// Output:
+	// 1 alice book
+	// 1 alice cup
+	// 3 carol pen
+	// 1 true true
+	// 1 true true
+	// 2 true false
+	// 3 true true
+	// 4 false true
+	// 1 alice book
+	// 3 carol pen
+	// 1 alice cup
+}
+
+func ExampleSemiJoin() {
+	users := slices.All([]string{"alice", "bob", "carol"})
+	banned := maps.All(map[int]bool{2: true, 0: true})
+	for id, user := range SemiJoin(users, banned) {
+		fmt.Println(id, user)
+	}
+	// Output:
+	// 0 alice
+	// 2 carol
+}
+
+func ExampleAntiJoin() {
+	users := slices.All([]string{"alice", "bob", "carol"})
+	banned := maps.All(map[int]bool{2: true, 0: true})
+	for id, user := range AntiJoin(users, banned) {
+		fmt.Println(id, user)
+	}
+	// Output: 1 bob
+}
+
+func ExampleSortedJoin() {
+	left := func(yield func(string, int) bool) {
+		_ = yield("a", 1) && yield("b", 2) && yield("b", 3)
+	}
+	right := func(yield func(string, string) bool) {
+		_ = yield("b", "x") && yield("b", "y") && yield("c", "z")
+	}
+	for r := range SortedJoin(left, right, FullJoin) {
+		fmt.Println(r.Key, r.Left, r.Right, r.HasLeft, r.HasRight)
+	}
+	// Output:
+	// a 1  true false
+	// b 2 x true true
+	// b 2 y true true
+	// b 3 x true true
+	// b 3 y true true
+	// c 0 z false true
+}
+
+func ExampleAlign() {
+	m := map[string]int{"a": 1, "b": 2}
+	n := map[string]string{"b": "x", "c": "y"}
+	records := slices.SortedFunc(Align(m, n), func(r1, r2 Joined[string, int, string]) int {
+		return strings.Compare(r1.Key, r2.Key)
+	})
+	for _, r := range records {
+		fmt.Println(r.Key, r.Left, r.Right, r.HasLeft, r.HasRight)
+	}
+	// Output:
+	// a 1  true false
+	// b 2 x true true
+	// c 0 y false true
+}
+
+func ExampleAlignSeq() {
+	m := map[string]int{"a": 1, "b": 2}
+	for r := range AlignSeq(m, maps.All(map[string]string{"b": "x"})) {
+		fmt.Println(r.Key, r.Left, r.Right, r.HasLeft, r.HasRight)
+	}
+	// Output:
+	// b 2 x true true
+	// a 1  true false
+}
+
+func ExampleCounter() {
+	c := Counter[string](Count(slices.Values(strings.Split("abracadabra", ""))))
+	fmt.Println(c.MostCommon(1), c.Total())
+	c.Subtract(slices.Values([]string{"a", "c"}))
+	fmt.Println(c)
+	fmt.Println(EqualCounts(c.Elements(), slices.Values(strings.Split("abbrrdaaa", ""))))
+	// Output:
+	// [a] 11
+	// map[a:4 b:2 d:1 r:2]
+	// true
+}
+
+func ExampleCounter_Union() {
+	c := Counter[string]{"a": 3, "b": 1}
+	counts := map[string]int{"a": 1, "b": 2, "c": 1}
+	fmt.Println(c.Union(counts))
+	fmt.Println(c.Intersect(counts))
+	fmt.Println(c.Sum(counts))
+	fmt.Println(c.Difference(counts))
+	// Output:
+	// map[a:3 b:2 c:1]
+	// map[a:1 b:1]
+	// map[a:4 b:3 c:1]
+	// map[a:2]
+}
+
+func ExampleOrderedMapSet() {
+	var s OrderedMapSet[string, int]
+	for i, key := range []string{"c", "a", "b"} {
+		s.Store(key, i)
+	}
+	s.Delete("a")
+	s.Add("a")
+	s.Store("c", 3)
+	fmt.Println(slices.Collect(s.Keys()))
+	for key, value := range s.Backward() {
+		fmt.Println(key, value)
+	}
+	// Output:
+	// [c b a]
+	// a 0
+	// b 2
+	// c 3
+}
+
+func ExampleOrderedMapSet_Difference() {
+	var s OrderedSet[string]
+	s.Add("d", "c", "b", "a")
+	keys := slices.Values([]string{"c", "e", "a"})
+	fmt.Println(slices.Collect(Keys(s.Difference(keys))))
+	fmt.Println(slices.Collect(s.SymmetricDifference(keys)))
+	// Output:
+	// [d b]
+	// [e d b]
+}
+
+func ExampleSortedMap() {
+	var s SortedSet[int]
+	s.Insert(slices.Values([]int{5, 1, 9, 3, 7}), struct{}{})
+	s.Add(4)
+	fmt.Println(slices.Collect(s.Keys()))
+	fmt.Println(slices.Collect(Keys(s.Range(3, 7))))
+	floor, _, _ := s.Floor(6)
+	ceiling, _, _ := s.Ceiling(6)
+	fmt.Println(floor, ceiling)
+	key, _, _ := s.Select(s.Rank(5))
+	fmt.Println(s.Rank(5), key)
+	fmt.Println(slices.Collect(SortedIntersect(s.Keys(), []int{1, 2, 3})))
+	// Output:
+	// [1 3 4 5 7 9]
+	// [3 4 5]
+	// 5 7
+	// 3 5
+	// [1 3]
+}
+
+func ExampleBitSet() {
+	var b BitSet
+	b.Add(3, 1, 64, 100)
+	b.Delete(100)
+	fmt.Println(slices.Collect(b.All()), b.Len(), b.Contains(64))
+	other := NewBitSet(slices.Values([]int{1, 2, 3}))
+	fmt.Println(slices.Collect(b.Intersect(other).All()))
+	fmt.Println(slices.Collect(b.SymmetricDifference(other).All()))
+	fmt.Println(Equal(b.All(), maps.Keys(b.MapSet())))
+	// Output:
+	// [1 3 64] 3 true
+	// [1 3]
+	// [2 64]
+	// true
+}
+
+func ExampleBitmap() {
+	b := NewBitmap(slices.Values([]uint32{1 << 20, 3, 1, 2}))
+	b.Optimize()
+	fmt.Println(slices.Collect(b.All()), b.Len())
+	fmt.Println(b.Rank(3), b.Contains(1<<20))
+	key, _ := b.Select(3)
+	fmt.Println(key)
+	other := NewBitmap(slices.Values([]uint32{2, 4, 1 << 20}))
+	fmt.Println(slices.Collect(b.Intersect(other).All()))
+	fmt.Println(slices.Collect(SortedDifference(b.All(), other.All())))
+	data, _ := b.MarshalBinary()
+	var c Bitmap
+	fmt.Println(c.UnmarshalBinary(data), c.Len())
+	// Output:
+	// [1 2 3 1048576] 4
+	// 2 true
+	// 1048576
+	// [2 1048576]
+	// [1 3]
+	// <nil> 4
+}
+
+func ExampleBloomFilter() {
+	f := NewBloomFilter[string](1000, 0.01, nil)
+	f.Insert(slices.Values([]string{"a", "b", "c"}))
+	fmt.Println(f.Contains("a"), f.Missing("b"))
+	keys := slices.DeleteFunc([]string{"a", "z", "c"}, f.Missing)
+	fmt.Println(keys)
+	// Output:
+	// true false
+	// [a c]
+}
+
+func ExampleCuckooFilter() {
+	f := NewCuckooFilter[string](1000, nil)
+	fmt.Println(f.Insert(slices.Values([]string{"a", "b", "c"})))
+	f.Delete("b")
+	fmt.Println(f.Contains("a"), f.Contains("b"), f.Len())
+	// Output:
+	// <nil>
+	// true false 2
+}
+
+func ExampleHyperLogLog() {
+	hash := func(key int) uint64 { // deterministic for reproducible estimates
+		x := uint64(key) + 0x9e3779b97f4a7c15
+		x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+		x = (x ^ x>>27) * 0x94d049bb133111eb
+		return x ^ x>>31
+	}
+	h1, h2 := NewHyperLogLog(14, hash), NewHyperLogLog(14, hash)
+	h1.Insert(Keys(slices.All(make([]int, 1000))))
+	h2.Add(2, 3, 3, 1000)
+	fmt.Println(h2.Len())
+	left, both, right, _ := h1.Overlap(h2)
+	fmt.Println(left, both, right)
+	// Output:
+	// 3
+	// 993 2 1
+}
+
+func ExampleCountMinSketch() {
+	s := NewCountMinSketch[string](0.01, 0.01, true, nil)
+	s.Insert(slices.Values(strings.Split("abracadabra", "")))
+	s.Add("z", 10)
+	fmt.Println(s.Estimate("a"), s.Estimate("z"), s.Estimate("x"))
+	// Output: 5 10 0
+}
+
+func ExampleTopK() {
+	t := NewTopK(2, NewCountMinSketch[string](0.01, 0.01, false, nil))
+	t.Insert(slices.Values(strings.Split("abracadabra", "")))
+	for key, count := range t.All() {
+		fmt.Println(key, count)
+	}
+	// Output:
+	// a 5
+	// b 2
+}
+
+func ExampleSpaceSaving() {
+	s := NewSpaceSaving[string](3)
+	s.Insert(slices.Values(strings.Split("abracadabra", "")))
+	for key := range Keys(s.All()) {
+		count, err := s.Estimate(key)
+		fmt.Println(key, count, err)
+	}
+	// Output:
+	// a 5 0
+	// r 3 2
+	// b 3 2
+}
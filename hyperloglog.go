@@ -0,0 +1,109 @@
+package iterset
+
+import (
+	"hash/maphash"
+	"iter"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// HyperLogLog is a sketch which estimates the number of distinct keys in constant space.
+// With precision p, it uses 2^p registers of 1 byte, and the standard error is 1.04 / √(2^p).
+type HyperLogLog[K comparable] struct {
+	registers []uint8
+	hash      func(K) uint64
+}
+
+// NewHyperLogLog returns an empty [HyperLogLog] with the precision in [4, 18].
+// If hash is nil, [maphash.Comparable] is used. Panics if the precision is out of range.
+func NewHyperLogLog[K comparable](precision int, hash func(K) uint64) *HyperLogLog[K] {
+	if precision < 4 || precision > 18 {
+		panic("iterset: precision must be in [4, 18]")
+	}
+	return &HyperLogLog[K]{registers: make([]uint8, 1<<precision), hash: hash}
+}
+
+func (h *HyperLogLog[K]) add(key K) {
+	var x uint64
+	if h.hash == nil {
+		x = maphash.Comparable(seed, key)
+	} else {
+		x = h.hash(key)
+	}
+	p := bits.Len(uint(len(h.registers))) - 1
+	i, rank := x>>(64-p), uint8(bits.LeadingZeros64(x<<p|1<<(p-1))+1)
+	h.registers[i] = max(h.registers[i], rank)
+}
+
+// Add key(s).
+func (h *HyperLogLog[K]) Add(keys ...K) {
+	for _, key := range keys {
+		h.add(key)
+	}
+}
+
+// Insert keys.
+func (h *HyperLogLog[K]) Insert(keys iter.Seq[K]) {
+	for key := range keys {
+		h.add(key)
+	}
+}
+
+// Len returns the estimated number of distinct keys.
+//
+// Performance:
+//   - time: O(2^p)
+func (h *HyperLogLog[K]) Len() int {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros += 1
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	switch m {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	}
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros)) // linear counting for small cardinalities
+	}
+	return int(math.Round(estimate))
+}
+
+// Merge adds the keys of the other sketch, in-place.
+// Returns [ErrMismatch] if the precisions differ. The sketches must also use the same hash.
+func (h *HyperLogLog[K]) Merge(other *HyperLogLog[K]) error {
+	if len(h.registers) != len(other.registers) {
+		return ErrMismatch
+	}
+	for i, r := range other.registers {
+		h.registers[i] = max(h.registers[i], r)
+	}
+	return nil
+}
+
+// Overlap returns the estimated sizes of the intersection and differences:
+// left only, both, right only. The intersection is estimated by inclusion-exclusion,
+// so its error is relative to the union, not the intersection.
+// Returns [ErrMismatch] if the precisions differ.
+//
+// Related:
+//   - [MapSet.Overlap] for exact sizes
+func (h *HyperLogLog[K]) Overlap(other *HyperLogLog[K]) (int, int, int, error) {
+	union := &HyperLogLog[K]{registers: slices.Clone(h.registers)}
+	if err := union.Merge(other); err != nil {
+		return 0, 0, 0, err
+	}
+	left, right := h.Len(), other.Len()
+	both := min(max(left+right-union.Len(), 0), left, right)
+	return left - both, both, right - both, nil
+}
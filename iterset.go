@@ -235,6 +235,7 @@ func (m MapSet[K, V]) ReverseDifference(keys iter.Seq[K]) iter.Seq[K] {
 //
 // Related:
 //   - [MapSet.Toggle] to modify in-place
+//   - [SortedSymmetricDifference] for sorted sequences
 //
 // Performance:
 //   - time: O(m+k)
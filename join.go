@@ -0,0 +1,305 @@
+package iterset
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Joined is a record of a key with its left and right values, and whether each is present.
+type Joined[K, V1, V2 any] struct {
+	Key      K
+	Left     V1
+	Right    V2
+	HasLeft  bool
+	HasRight bool
+}
+
+// JoinMode specifies which unmatched records are retained in a join.
+type JoinMode uint8
+
+const (
+	InnerJoin JoinMode = iota // only matched records
+	LeftJoin                  // and unmatched left records
+	RightJoin                 // and unmatched right records
+	FullJoin                  // and all unmatched records
+
+	BuildLeft JoinMode = 1 << 2 // flag for [HashJoin] to build the map from the left side
+)
+
+// HashJoin returns the records of pairs with matching keys.
+// The smaller side should be built into a map, which the other side probes lazily.
+// By default the right side is built; add the [BuildLeft] flag to build the left side instead.
+// Records are in probe order, followed by unmatched build records in map order.
+//
+// Related:
+//   - [SemiJoin] and [AntiJoin] to filter by keys
+//   - [Group] to build a map of either side
+//   - [SortedJoin] if both sides are sorted by key
+//
+// Performance:
+//   - time: O(m+k)
+//   - space: O(m) for the built side
+func HashJoin[K comparable, V1, V2 any](
+	left iter.Seq2[K, V1], right iter.Seq2[K, V2], mode JoinMode,
+) iter.Seq[Joined[K, V1, V2]] {
+	if mode&BuildLeft != 0 {
+		record := func(key K, v2 V2, v1 V1, ok2, ok1 bool) Joined[K, V1, V2] {
+			return Joined[K, V1, V2]{key, v1, v2, ok1, ok2}
+		}
+		return hashJoin(right, left, mode&RightJoin != 0, mode&LeftJoin != 0, record)
+	}
+	record := func(key K, v1 V1, v2 V2, ok1, ok2 bool) Joined[K, V1, V2] {
+		return Joined[K, V1, V2]{key, v1, v2, ok1, ok2}
+	}
+	return hashJoin(left, right, mode&LeftJoin != 0, mode&RightJoin != 0, record)
+}
+
+// hashJoin probes a map built from one side, retaining unmatched records from either side.
+func hashJoin[K comparable, P, B, R any](
+	probe iter.Seq2[K, P], build iter.Seq2[K, B], outerProbe, outerBuild bool,
+	record func(K, P, B, bool, bool) R,
+) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		var p P
+		var b B
+		m := Group(build)
+		var matched MapSet[K, struct{}]
+		if outerBuild {
+			matched = Set[K]()
+		}
+		for key, value := range probe {
+			group, ok := m[key]
+			if !ok {
+				if outerProbe && !yield(record(key, value, b, true, false)) {
+					return
+				}
+				continue
+			}
+			if matched != nil {
+				matched.add(key)
+			}
+			for _, other := range group {
+				if !yield(record(key, value, other, true, true)) {
+					return
+				}
+			}
+		}
+		if matched == nil || len(matched) == len(m) {
+			return
+		}
+		for key, group := range m.filter(matched.Missing) {
+			for _, other := range group {
+				if !yield(record(key, p, other, false, true)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// SemiJoin returns the ordered left pairs whose keys are present on the right.
+// The keys of the right side are built into a set, which the left side probes lazily.
+//
+// Related:
+//   - [MapSet.Intersect] if the pairs were a map
+//
+// Performance:
+//   - time: O(k+n)
+//   - space: O(k)
+func SemiJoin[K comparable, V1, V2 any](
+	left iter.Seq2[K, V1], right iter.Seq2[K, V2],
+) iter.Seq2[K, V1] {
+	return func(yield func(K, V1) bool) {
+		s := Collect(Keys(right), struct{}{})
+		if len(s) == 0 {
+			return
+		}
+		for key, value := range left {
+			if s.Contains(key) && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// AntiJoin returns the ordered left pairs whose keys are not present on the right.
+// The keys of the right side are built into a set, which the left side probes lazily.
+//
+// Related:
+//   - [MapSet.Difference] if the pairs were a map
+//
+// Performance:
+//   - time: O(k+n)
+//   - space: O(k)
+func AntiJoin[K comparable, V1, V2 any](
+	left iter.Seq2[K, V1], right iter.Seq2[K, V2],
+) iter.Seq2[K, V1] {
+	return func(yield func(K, V1) bool) {
+		s := Collect(Keys(right), struct{}{})
+		for key, value := range left {
+			if !s.Contains(key) && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// SortedJoin returns the records of pairs with matching keys, from sides sorted by key.
+// Duplicate keys on both sides are matched as a cross product.
+// Only the current run of right values with an equal key is buffered.
+// The [BuildLeft] flag is ignored.
+//
+// Related:
+//   - [HashJoin] if the sides are not sorted
+//
+// Performance:
+//   - time: O(m+k)
+//   - space: O(r) for the longest run of equal right keys
+func SortedJoin[K cmp.Ordered, V1, V2 any](
+	left iter.Seq2[K, V1], right iter.Seq2[K, V2], mode JoinMode,
+) iter.Seq[Joined[K, V1, V2]] {
+	return SortedJoinFunc(left, right, cmp.Compare, mode)
+}
+
+// SortedJoinFunc is like [SortedJoin] but uses a comparison function.
+//
+// Performance:
+//   - time: O(m+k)
+//   - space: O(r) for the longest run of equal right keys
+func SortedJoinFunc[K, V1, V2 any](
+	left iter.Seq2[K, V1], right iter.Seq2[K, V2], compare func(K, K) int, mode JoinMode,
+) iter.Seq[Joined[K, V1, V2]] {
+	return func(yield func(Joined[K, V1, V2]) bool) {
+		nextLeft, stop := iter.Pull2(left)
+		defer stop()
+		nextRight, stop := iter.Pull2(right)
+		defer stop()
+		k1, v1, ok1 := nextLeft()
+		k2, v2, ok2 := nextRight()
+		var run []V2
+		for (ok1 && (ok2 || mode&LeftJoin != 0)) || (ok2 && mode&RightJoin != 0) {
+			c := 1
+			if ok1 && ok2 {
+				c = compare(k1, k2)
+			} else if ok1 {
+				c = -1
+			}
+			switch {
+			case c < 0:
+				record := Joined[K, V1, V2]{Key: k1, Left: v1, HasLeft: true}
+				if mode&LeftJoin != 0 && !yield(record) {
+					return
+				}
+				k1, v1, ok1 = nextLeft()
+			case c > 0:
+				record := Joined[K, V1, V2]{Key: k2, Right: v2, HasRight: true}
+				if mode&RightJoin != 0 && !yield(record) {
+					return
+				}
+				k2, v2, ok2 = nextRight()
+			default:
+				key := k2
+				run = run[:0]
+				for ok2 && compare(key, k2) == 0 {
+					run = append(run, v2)
+					k2, v2, ok2 = nextRight()
+				}
+				for ok1 && compare(k1, key) == 0 {
+					for _, v := range run {
+						if !yield(Joined[K, V1, V2]{k1, v1, v, true, true}) {
+							return
+						}
+					}
+					k1, v1, ok1 = nextLeft()
+				}
+			}
+		}
+	}
+}
+
+// Align returns a record for every key in either map, with flags for whether each side is present.
+// The larger map is walked first, so the smaller can be skipped if all its keys matched.
+//
+// Related:
+//   - [MapSet.Overlap] for the counts of a map and a sequence
+//   - [AlignSeq] for a map and a sequence of pairs
+//
+// Performance:
+//   - time: O(m+n)
+func Align[K comparable, V1, V2 any](m map[K]V1, n map[K]V2) iter.Seq[Joined[K, V1, V2]] {
+	return func(yield func(Joined[K, V1, V2]) bool) {
+		count := 0
+		if len(m) >= len(n) {
+			for key, v1 := range m {
+				v2, ok := n[key]
+				if ok {
+					count += 1
+				}
+				if !yield(Joined[K, V1, V2]{key, v1, v2, true, ok}) {
+					return
+				}
+			}
+			for key, v2 := range n {
+				if count == len(n) {
+					return
+				}
+				if _, ok := m[key]; !ok && !yield(Joined[K, V1, V2]{Key: key, Right: v2, HasRight: true}) {
+					return
+				}
+			}
+			return
+		}
+		for key, v2 := range n {
+			v1, ok := m[key]
+			if ok {
+				count += 1
+			}
+			if !yield(Joined[K, V1, V2]{key, v1, v2, ok, true}) {
+				return
+			}
+		}
+		for key, v1 := range m {
+			if count == len(m) {
+				return
+			}
+			if _, ok := n[key]; !ok && !yield(Joined[K, V1, V2]{Key: key, Left: v1, HasLeft: true}) {
+				return
+			}
+		}
+	}
+}
+
+// AlignSeq returns a record for every pair in the sequence, with its matching map value,
+// followed by the map keys which were not in the sequence.
+//
+// Related:
+//   - [Align] for two maps
+//   - [HashJoin] to group duplicate keys
+//
+// Performance:
+//   - time: O(m+k)
+//   - space: O(min(m, k))
+func AlignSeq[K comparable, V1, V2 any](
+	m map[K]V1, seq iter.Seq2[K, V2],
+) iter.Seq[Joined[K, V1, V2]] {
+	return func(yield func(Joined[K, V1, V2]) bool) {
+		s := Set[K]()
+		for key, v2 := range seq {
+			v1, ok := m[key]
+			if ok {
+				s.add(key)
+			}
+			if !yield(Joined[K, V1, V2]{key, v1, v2, ok, true}) {
+				return
+			}
+		}
+		if len(s) == len(m) {
+			return
+		}
+		for key, v1 := range m {
+			if !s.Contains(key) && !yield(Joined[K, V1, V2]{Key: key, Left: v1, HasLeft: true}) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package iterset
+
+import (
+	"iter"
+	"slices"
+)
+
+// OrderedMapSet is a [MapSet] which iterates in insertion order.
+// Updating the value of a present key retains its position.
+// The zero value is an empty set ready to use.
+//
+// Keys are indexed into a slice of entries, so membership is O(1).
+// Deleted entries are marked and compacted once they outnumber the keys,
+// so deletion is amortized O(1). Compaction is deferred while iterating,
+// so keys may be added or deleted during iteration.
+type OrderedMapSet[K comparable, V any] struct {
+	index   MapSet[K, int]
+	entries []entry[K, V]
+	iters   int
+}
+
+// OrderedSet is an [OrderedMapSet] with empty values.
+type OrderedSet[K comparable] = OrderedMapSet[K, struct{}]
+
+type entry[K, V any] struct {
+	key     K
+	value   V
+	deleted bool
+}
+
+// NewOrderedMapSet returns an [OrderedMapSet] of the pairs in order.
+// Duplicate keys overwrite values, retaining the first position.
+func NewOrderedMapSet[K comparable, V any](seq iter.Seq2[K, V]) *OrderedMapSet[K, V] {
+	s := &OrderedMapSet[K, V]{}
+	for key, value := range seq {
+		s.Store(key, value)
+	}
+	return s
+}
+
+func (s *OrderedMapSet[K, V]) delete(key K) {
+	i, ok := s.index[key]
+	if !ok {
+		return
+	}
+	delete(s.index, key)
+	s.entries[i] = entry[K, V]{deleted: true}
+	s.compact()
+}
+
+func (s *OrderedMapSet[K, V]) compact() {
+	if s.iters > 0 || len(s.entries) <= 2*len(s.index) {
+		return
+	}
+	s.entries = slices.DeleteFunc(s.entries, func(e entry[K, V]) bool { return e.deleted })
+	for i, e := range s.entries {
+		s.index[e.key] = i
+	}
+}
+
+func (s *OrderedMapSet[K, V]) iterate() func() {
+	s.iters += 1
+	return func() {
+		s.iters -= 1
+		s.compact()
+	}
+}
+
+// Len returns the number of keys.
+func (s *OrderedMapSet[K, V]) Len() int {
+	return len(s.index)
+}
+
+// Get returns the value and whether the key is present.
+func (s *OrderedMapSet[K, V]) Get(key K) (V, bool) {
+	i, ok := s.index[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return s.entries[i].value, true
+}
+
+// Store sets the value of the key, appending it if it is not present.
+func (s *OrderedMapSet[K, V]) Store(key K, value V) {
+	if i, ok := s.index[key]; ok {
+		s.entries[i].value = value
+		return
+	}
+	if s.index == nil {
+		s.index = MapSet[K, int]{}
+	}
+	s.index[key] = len(s.entries)
+	s.entries = append(s.entries, entry[K, V]{key: key, value: value})
+}
+
+// Clone returns a compacted copy.
+func (s *OrderedMapSet[K, V]) Clone() *OrderedMapSet[K, V] {
+	return NewOrderedMapSet(s.All())
+}
+
+// All returns the key-value pairs in insertion order.
+func (s *OrderedMapSet[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		defer s.iterate()()
+		for i := 0; i < len(s.entries); i++ {
+			e := s.entries[i]
+			if !e.deleted && !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns the keys in insertion order.
+func (s *OrderedMapSet[K, V]) Keys() iter.Seq[K] {
+	return Keys(s.All())
+}
+
+// Backward returns the key-value pairs in reverse insertion order.
+func (s *OrderedMapSet[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		defer s.iterate()()
+		for i := len(s.entries) - 1; i >= 0; i-- {
+			e := s.entries[i]
+			if !e.deleted && !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Contains returns whether the key is present.
+func (s *OrderedMapSet[K, V]) Contains(key K) bool {
+	return s.index.Contains(key)
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (s *OrderedMapSet[K, V]) Missing(key K) bool {
+	return s.index.Missing(key)
+}
+
+// IsSuperset returns whether all keys are present.
+func (s *OrderedMapSet[K, V]) IsSuperset(keys iter.Seq[K]) bool {
+	return allFunc(keys, s.Contains)
+}
+
+// Equal returns whether the key sets are equivalent.
+func (s *OrderedMapSet[K, V]) Equal(keys iter.Seq[K]) bool {
+	return s.index.equal(keys)
+}
+
+// IsSubset returns whether every key is present in keys.
+func (s *OrderedMapSet[K, V]) IsSubset(keys iter.Seq[K]) bool {
+	return len(s.index) == len(s.index.intersect(keys))
+}
+
+// IsDisjoint returns whether no keys are present.
+func (s *OrderedMapSet[K, V]) IsDisjoint(keys iter.Seq[K]) bool {
+	return len(s.index) == 0 || allFunc(keys, s.Missing)
+}
+
+// Add key(s) with zero value.
+func (s *OrderedMapSet[K, V]) Add(keys ...K) {
+	var value V
+	for _, key := range keys {
+		s.Store(key, value)
+	}
+}
+
+// Insert keys with default value.
+func (s *OrderedMapSet[K, V]) Insert(keys iter.Seq[K], value V) {
+	for key := range keys {
+		s.Store(key, value)
+	}
+}
+
+// Delete key(s).
+func (s *OrderedMapSet[K, V]) Delete(keys ...K) {
+	for _, key := range keys {
+		s.delete(key)
+	}
+}
+
+// Remove keys.
+func (s *OrderedMapSet[K, V]) Remove(keys iter.Seq[K]) {
+	for key := range keys {
+		s.delete(key)
+	}
+}
+
+// Toggle removes present keys, and appends missing keys.
+func (s *OrderedMapSet[K, V]) Toggle(keys iter.Seq[K], value V) {
+	for key := range keys {
+		if s.Contains(key) {
+			s.delete(key)
+		} else {
+			s.Store(key, value)
+		}
+	}
+}
+
+// Keep only the keys present in both, retaining their order.
+func (s *OrderedMapSet[K, V]) Keep(keys iter.Seq[K]) {
+	m := s.index.intersect(keys)
+	if len(m) == len(s.index) {
+		return
+	}
+	for i, e := range s.entries {
+		if !e.deleted && m.Missing(e.key) {
+			delete(s.index, e.key)
+			s.entries[i] = entry[K, V]{deleted: true}
+		}
+	}
+	s.compact()
+}
+
+// Union returns a copy with successive inserts.
+// Duplicate keys overwrite values, retaining their position.
+func (s *OrderedMapSet[K, V]) Union(seqs ...iter.Seq2[K, V]) *OrderedMapSet[K, V] {
+	s = s.Clone()
+	for _, seq := range seqs {
+		for key, value := range seq {
+			s.Store(key, value)
+		}
+	}
+	return s
+}
+
+// Intersect returns the ordered key-value pairs which are present in both.
+func (s *OrderedMapSet[K, V]) Intersect(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key := range keys {
+			value, ok := s.Get(key)
+			if ok && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// IntersectCount returns the number of keys present in both.
+func (s *OrderedMapSet[K, V]) IntersectCount(keys iter.Seq[K]) int {
+	return s.index.intersectCount(keys)
+}
+
+// Difference returns the key-value pairs in insertion order which are not present in the keys.
+func (s *OrderedMapSet[K, V]) Difference(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m := s.index.intersect(keys)
+		for key, value := range s.All() {
+			if m.Missing(key) && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseDifference returns the ordered keys which are not present in the set.
+func (s *OrderedMapSet[K, V]) ReverseDifference(keys iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		keys(func(key K) bool { return s.Contains(key) || yield(key) })
+	}
+}
+
+// SymmetricDifference returns the ordered keys which are not present in the set,
+// followed by the keys in insertion order which are not present in the keys.
+func (s *OrderedMapSet[K, V]) SymmetricDifference(keys iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m := Set[K]()
+		for key := range keys {
+			if s.Contains(key) {
+				m.add(key)
+			} else if !yield(key) {
+				return
+			}
+		}
+		for key := range s.Keys() {
+			if m.Missing(key) && !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Overlap returns the sizes of the intersection and differences:
+// left only, both, right only.
+func (s *OrderedMapSet[K, V]) Overlap(keys iter.Seq[K]) (int, int, int) {
+	return s.index.Overlap(keys)
+}
@@ -0,0 +1,437 @@
+package iterset
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+const batchSize = 1024
+
+// goCollect adds values to partial maps in parallel workers, and merges them in order.
+// Slices are partitioned into contiguous chunks.
+// Sequences are sent to workers in batches, which are merged in order as they complete.
+func goCollect[K comparable, V, E any, I iterable[E]](
+	values I, workers int, add func(MapSet[K, V], E), merge func(MapSet[K, V], K, V),
+) MapSet[K, V] {
+	workers = cmp.Or(max(workers, 0), runtime.GOMAXPROCS(0))
+	var parts []MapSet[K, V]
+	switch values := any(values).(type) {
+	case []E:
+		parts = make([]MapSet[K, V], workers)
+		var wg sync.WaitGroup
+		for i := range parts {
+			chunk := values[i*len(values)/workers : (i+1)*len(values)/workers]
+			wg.Go(func() {
+				parts[i] = sized[K, V, E](chunk)
+				for _, value := range chunk {
+					add(parts[i], value)
+				}
+			})
+		}
+		wg.Wait()
+	case iter.Seq[E]:
+		return goCollectSeq(values, workers, add, merge)
+	}
+	m := parts[0]
+	for _, part := range parts[1:] {
+		for key, value := range part {
+			merge(m, key, value)
+		}
+	}
+	return m
+}
+
+// goCollectSeq sends numbered batches to workers, and merges their partial maps in order.
+// Batches which complete out of order are held, and the number in flight is bounded.
+func goCollectSeq[K comparable, V, E any](
+	values iter.Seq[E], workers int, add func(MapSet[K, V], E), merge func(MapSet[K, V], K, V),
+) MapSet[K, V] {
+	type batch struct {
+		index  int
+		values []E
+		part   MapSet[K, V]
+	}
+	jobs, results := make(chan batch, workers), make(chan batch, workers)
+	slots := make(chan struct{}, 2*workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for b := range jobs {
+				b.part = MapSet[K, V]{}
+				for _, value := range b.values {
+					add(b.part, value)
+				}
+				results <- b
+			}
+		})
+	}
+	merged := make(chan MapSet[K, V])
+	go func() {
+		var m MapSet[K, V]
+		pending, next := map[int]MapSet[K, V]{}, 0
+		for b := range results {
+			pending[b.index] = b.part
+			for part, ok := pending[next]; ok; part, ok = pending[next] {
+				delete(pending, next)
+				next += 1
+				<-slots
+				if m == nil {
+					m = part
+					continue
+				}
+				for key, value := range part {
+					merge(m, key, value)
+				}
+			}
+		}
+		merged <- m
+	}()
+	b := batch{values: make([]E, 0, batchSize)}
+	for value := range values {
+		b.values = append(b.values, value)
+		if len(b.values) == batchSize {
+			slots <- struct{}{}
+			jobs <- b
+			b = batch{index: b.index + 1, values: make([]E, 0, batchSize)}
+		}
+	}
+	slots <- struct{}{}
+	jobs <- b
+	close(jobs)
+	wg.Wait()
+	close(results)
+	return <-merged
+}
+
+// GoCount is a parallel [Count] with the given number of workers.
+// If workers <= 0, the number of workers is [runtime.GOMAXPROCS].
+//
+// Related:
+//   - [GoIter] to iterate in a single background goroutine
+func GoCount[K comparable, S iter.Seq[K] | []K](keys S, workers int) MapSet[K, int] {
+	add := func(m MapSet[K, int], key K) { m[key] += 1 }
+	merge := func(m MapSet[K, int], key K, count int) { m[key] += count }
+	return goCollect(keys, workers, add, merge)
+}
+
+// GoIndexBy is a parallel [IndexBy] with the given number of workers.
+// If workers <= 0, the number of workers is [runtime.GOMAXPROCS].
+// If there are collisions, the last value remains.
+func GoIndexBy[K comparable, V any, S iter.Seq[V] | []V](
+	values S, key func(V) K, workers int,
+) MapSet[K, V] {
+	add := func(m MapSet[K, V], value V) { m[key(value)] = value }
+	merge := func(m MapSet[K, V], k K, value V) { m[k] = value }
+	return goCollect(values, workers, add, merge)
+}
+
+// GoGroupBy is a parallel [GroupBy] with the given number of workers.
+// If workers <= 0, the number of workers is [runtime.GOMAXPROCS].
+// Groups retain the original order.
+func GoGroupBy[K comparable, V any, S iter.Seq[V] | []V](
+	values S, key func(V) K, workers int,
+) MapSet[K, []V] {
+	add := func(m MapSet[K, []V], value V) {
+		k := key(value)
+		m[k] = append(m[k], value)
+	}
+	merge := func(m MapSet[K, []V], k K, group []V) { m[k] = append(m[k], group...) }
+	return goCollect(values, workers, add, merge)
+}
+
+// GoReduce is a parallel [Reduce] which groups values by key function,
+// with the given number of workers.
+// If workers <= 0, the number of workers is [runtime.GOMAXPROCS].
+// The binary function must be associative, as partial results are combined in order.
+func GoReduce[K comparable, V any, S iter.Seq[V] | []V](
+	values S, key func(V) K, f func(V, V) V, workers int,
+) MapSet[K, V] {
+	merge := func(m MapSet[K, V], k K, value V) {
+		v, ok := m[k]
+		if ok {
+			value = f(v, value)
+		}
+		m[k] = value
+	}
+	add := func(m MapSet[K, V], value V) { merge(m, key(value), value) }
+	return goCollect(values, workers, add, merge)
+}
+
+func goBatch[V any](ctx context.Context, seq iter.Seq[V], size, n int, flush bool) <-chan []V {
+	if flush {
+		return goFlush(ctx, seq, size, n)
+	}
+	ch := make(chan []V, size)
+	go func() {
+		defer close(ch)
+		batch := make([]V, 0, n)
+		for value := range seq {
+			if ctx.Err() != nil {
+				return
+			}
+			batch = append(batch, value)
+			if len(batch) < n {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- batch:
+			}
+			batch = make([]V, 0, n)
+		}
+		if len(batch) > 0 {
+			select {
+			case <-ctx.Done():
+			case ch <- batch:
+			}
+		}
+	}()
+	return ch
+}
+
+// notify signals the channel without blocking, if it is not already signaled.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// goFlush sends values in batches of up to n, and sends a partial batch whenever the channel is ready.
+// The producer appends to a pending batch which a separate goroutine takes and sends,
+// so there is one channel send per batch, and a blocked producer does not hold a partial batch.
+func goFlush[V any](ctx context.Context, seq iter.Seq[V], size, n int) <-chan []V {
+	ch := make(chan []V, size)
+	var mu sync.Mutex
+	var pending []V
+	done := false
+	ready, room := make(chan struct{}, 1), make(chan struct{}, 1)
+	go func() {
+		defer func() {
+			mu.Lock()
+			done = true
+			mu.Unlock()
+			notify(ready)
+		}()
+		for value := range seq {
+			if ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			for len(pending) == n {
+				mu.Unlock()
+				select {
+				case <-ctx.Done():
+					return
+				case <-room:
+				}
+				mu.Lock()
+			}
+			if pending == nil {
+				pending = make([]V, 0, n)
+			}
+			pending = append(pending, value)
+			first := len(pending) == 1
+			mu.Unlock()
+			if first {
+				notify(ready)
+			}
+		}
+	}()
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ready:
+			}
+			mu.Lock()
+			batch, finished := pending, done
+			pending = nil
+			mu.Unlock()
+			notify(room)
+			if len(batch) > 0 && !send(ctx, ch, batch) || finished {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// GoBatch is like [GoIter] but sends batches of up to n values through the channel,
+// which amortizes its overhead for cheap values.
+// If n <= 0, a default batch size is used.
+// If flush is true, a partial batch is also sent whenever the channel is ready,
+// i.e., when the consumer is idle, so slow sequences are not delayed by batching.
+// Batches then grow only while the consumer is busy, so the overhead is amortized for slow consumers.
+func GoBatch[V any](ctx context.Context, seq iter.Seq[V], size, n int, flush bool) iter.Seq[V] {
+	n = cmp.Or(max(n, 0), batchSize)
+	return func(yield func(V) bool) {
+		child, cancel := context.WithCancel(ctx)
+		defer cancel()
+		for batch := range goBatch(child, seq, size, n, flush) {
+			for _, value := range batch {
+				if ctx.Err() != nil || !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func recv[V any](ctx context.Context, ch <-chan V) (V, bool) {
+	select {
+	case <-ctx.Done():
+		var zero V
+		return zero, false
+	case value, ok := <-ch:
+		return value, ok
+	}
+}
+
+func send[V any](ctx context.Context, ch chan<- V, value V) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case ch <- value:
+		return true
+	}
+}
+
+// GoMap applies a function to the sequence in a pool of background workers,
+// retaining the original order.
+// If workers <= 0, the number of workers is [runtime.GOMAXPROCS].
+// Iteration and all workers stop when the context is canceled or the iteration exits.
+//
+// Related:
+//   - [GoMapUnordered] to yield results as soon as they are ready
+//   - [GoIter] to iterate the sequence in a single background goroutine
+func GoMap[V, W any](ctx context.Context, seq iter.Seq[V], workers int, f func(V) W) iter.Seq[W] {
+	type job struct {
+		value  V
+		result chan W
+	}
+	workers = cmp.Or(max(workers, 0), runtime.GOMAXPROCS(0))
+	return func(yield func(W) bool) {
+		child, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+		jobs, results := make(chan job), make(chan chan W, workers)
+		for range workers {
+			wg.Go(func() {
+				for j, ok := recv(child, jobs); ok; j, ok = recv(child, jobs) {
+					j.result <- f(j.value)
+				}
+			})
+		}
+		go func() {
+			defer close(results)
+			for value := range seq {
+				j := job{value, make(chan W, 1)}
+				if !send(child, results, j.result) || !send(child, jobs, j) {
+					return
+				}
+			}
+		}()
+		for result, ok := recv(child, results); ok; result, ok = recv(child, results) {
+			value, ok := recv(child, result)
+			if !ok || ctx.Err() != nil || !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// GoMapUnordered is like [GoMap] but yields results as soon as they are ready,
+// in arbitrary order.
+func GoMapUnordered[V, W any](
+	ctx context.Context, seq iter.Seq[V], workers int, f func(V) W,
+) iter.Seq[W] {
+	workers = cmp.Or(max(workers, 0), runtime.GOMAXPROCS(0))
+	return func(yield func(W) bool) {
+		child, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+		values, results := goChan(child, seq, workers), make(chan W, workers)
+		for range workers {
+			wg.Go(func() {
+				for value, ok := recv(child, values); ok; value, ok = recv(child, values) {
+					if !send(child, results, f(value)) {
+						return
+					}
+				}
+			})
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+		for result := range results {
+			if ctx.Err() != nil || !yield(result) {
+				return
+			}
+		}
+	}
+}
+
+// GoIter2 is like [GoIter] for a sequence of pairs.
+func GoIter2[K, V any](ctx context.Context, seq iter.Seq2[K, V], size int) iter.Seq2[K, V] {
+	type pair struct {
+		key   K
+		value V
+	}
+	pairs := func(yield func(pair) bool) {
+		seq(func(key K, value V) bool { return yield(pair{key, value}) })
+	}
+	return func(yield func(K, V) bool) {
+		for p := range GoIter(ctx, pairs, size) {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	}
+}
+
+// GoIterErr is like [GoIter] for a producer which can fail.
+// The returned function reports the error of the last iteration, after it has finished.
+// Iteration waits for the producer to return, so its error is never dropped.
+// If the producer was stopped by the context, and did not return an error,
+// the context's cause is reported instead.
+func GoIterErr[V any](
+	ctx context.Context, seq func(yield func(V) bool) error, size int,
+) (iter.Seq[V], func() error) {
+	var err error
+	it := func(yield func(V) bool) {
+		child, cancel := context.WithCancel(ctx)
+		ch, done := make(chan V, size), make(chan struct{})
+		go func() {
+			defer close(done)
+			defer close(ch)
+			stopped := false
+			err = seq(func(value V) bool {
+				stopped = child.Err() != nil || !send(child, ch, value)
+				return !stopped
+			})
+			if err == nil && stopped && ctx.Err() != nil {
+				err = context.Cause(ctx)
+			}
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+		for value := range ch {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+	return it, func() error { return err }
+}
@@ -0,0 +1,608 @@
+package iterset
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// ErrEncoding is returned when unmarshaling invalid binary data.
+var ErrEncoding = errors.New("iterset: invalid encoding")
+
+// Container kinds, and the maximum cardinality of an array container.
+const (
+	arrayKind uint8 = iota
+	bitmapKind
+	runKind
+	arrayMax = 4096
+)
+
+// container holds the low 16 bits of keys which share their high 16 bits.
+// Arrays are sorted values, bitmaps are 1024 words, and runs are inclusive intervals.
+// Run containers are only created by [Bitmap.Optimize], and are expanded before mutation.
+type container struct {
+	kind   uint8
+	n      int
+	values []uint16
+	words  []uint64
+	runs   [][2]uint16
+}
+
+func (c *container) all() iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		switch c.kind {
+		case arrayKind:
+			for _, value := range c.values {
+				if !yield(value) {
+					return
+				}
+			}
+		case bitmapKind:
+			for i, w := range c.words {
+				for ; w != 0; w &= w - 1 {
+					if !yield(uint16(i*64 + bits.TrailingZeros64(w))) {
+						return
+					}
+				}
+			}
+		case runKind:
+			for _, r := range c.runs {
+				for value := int(r[0]); value <= int(r[1]); value++ {
+					if !yield(uint16(value)) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *container) contains(value uint16) bool {
+	switch c.kind {
+	case arrayKind:
+		_, found := slices.BinarySearch(c.values, value)
+		return found
+	case bitmapKind:
+		return c.words[value/64]&(1<<(value%64)) != 0
+	}
+	i := c.run(value)
+	return i < len(c.runs) && c.runs[i][0] <= value
+}
+
+// run returns the index of the first run which ends at or after the value.
+func (c *container) run(value uint16) int {
+	i, _ := slices.BinarySearchFunc(c.runs, value, func(r [2]uint16, value uint16) int {
+		return cmp.Compare(r[1], value)
+	})
+	return i
+}
+
+// toArray converts the container to an array, in-place.
+func (c *container) toArray() {
+	c.values = slices.AppendSeq(make([]uint16, 0, c.n), c.all())
+	c.kind, c.words, c.runs = arrayKind, nil, nil
+}
+
+// toBitmap converts the container to a bitmap, in-place.
+func (c *container) toBitmap() {
+	words := make([]uint64, 1024)
+	for value := range c.all() {
+		words[value/64] |= 1 << (value % 64)
+	}
+	c.kind, c.values, c.words, c.runs = bitmapKind, nil, words, nil
+}
+
+// expand converts a run container to an array or bitmap, in-place.
+func (c *container) expand() {
+	if c.kind != runKind {
+		return
+	}
+	if c.n <= arrayMax {
+		c.toArray()
+	} else {
+		c.toBitmap()
+	}
+}
+
+// expanded returns the container, or an expanded copy if it is a run container.
+func (c *container) expanded() *container {
+	if c.kind != runKind {
+		return c
+	}
+	e := *c
+	e.expand()
+	return &e
+}
+
+func (c *container) add(value uint16) bool {
+	c.expand()
+	switch c.kind {
+	case arrayKind:
+		i, found := slices.BinarySearch(c.values, value)
+		if found {
+			return false
+		}
+		if len(c.values) < arrayMax {
+			c.values = slices.Insert(c.values, i, value)
+			c.n += 1
+			return true
+		}
+		c.toBitmap()
+	}
+	if c.contains(value) {
+		return false
+	}
+	c.words[value/64] |= 1 << (value % 64)
+	c.n += 1
+	return true
+}
+
+func (c *container) remove(value uint16) bool {
+	if !c.contains(value) {
+		return false
+	}
+	c.expand()
+	c.n -= 1
+	switch c.kind {
+	case arrayKind:
+		i, _ := slices.BinarySearch(c.values, value)
+		c.values = slices.Delete(c.values, i, i+1)
+	case bitmapKind:
+		c.words[value/64] &^= 1 << (value % 64)
+		if c.n <= arrayMax {
+			c.toArray()
+		}
+	}
+	return true
+}
+
+// rank returns the number of values < value.
+func (c *container) rank(value uint16) int {
+	switch c.kind {
+	case arrayKind:
+		i, _ := slices.BinarySearch(c.values, value)
+		return i
+	case bitmapKind:
+		count := 0
+		for _, w := range c.words[:value/64] {
+			count += bits.OnesCount64(w)
+		}
+		return count + bits.OnesCount64(c.words[value/64]&(1<<(value%64)-1))
+	}
+	count, i := 0, c.run(value)
+	for _, r := range c.runs[:i] {
+		count += int(r[1]-r[0]) + 1
+	}
+	if i < len(c.runs) && c.runs[i][0] < value {
+		count += int(value - c.runs[i][0])
+	}
+	return count
+}
+
+// at returns the value at the index, which must be < n.
+func (c *container) at(index int) uint16 {
+	switch c.kind {
+	case arrayKind:
+		return c.values[index]
+	case bitmapKind:
+		for i := 0; ; i++ {
+			w := c.words[i]
+			if count := bits.OnesCount64(w); index >= count {
+				index -= count
+				continue
+			}
+			for range index {
+				w &= w - 1
+			}
+			return uint16(i*64 + bits.TrailingZeros64(w))
+		}
+	}
+	for i := 0; ; i++ {
+		r := c.runs[i]
+		if size := int(r[1]-r[0]) + 1; index >= size {
+			index -= size
+			continue
+		}
+		return r[0] + uint16(index)
+	}
+}
+
+// optimize converts the container to its smallest kind.
+func (c *container) optimize() {
+	var runs [][2]uint16
+	for value := range c.all() {
+		if last := len(runs) - 1; last >= 0 && int(runs[last][1])+1 == int(value) {
+			runs[last][1] = value
+		} else {
+			runs = append(runs, [2]uint16{value, value})
+		}
+	}
+	if size := 4 * len(runs); size < 2*c.n && size < 8*1024 {
+		c.kind, c.values, c.words, c.runs = runKind, nil, nil, runs
+	} else {
+		c.expand()
+	}
+}
+
+func (c *container) clone() *container {
+	return &container{c.kind, c.n, slices.Clone(c.values), slices.Clone(c.words), slices.Clone(c.runs)}
+}
+
+func (c *container) union(other *container) *container {
+	c, other = c.expanded(), other.expanded()
+	if c.kind == arrayKind && other.kind == arrayKind && c.n+other.n <= arrayMax {
+		values := make([]uint16, 0, c.n+other.n)
+		i, j := 0, 0
+		for i < len(c.values) && j < len(other.values) {
+			switch v1, v2 := c.values[i], other.values[j]; {
+			case v1 < v2:
+				values = append(values, v1)
+				i += 1
+			case v1 > v2:
+				values = append(values, v2)
+				j += 1
+			default:
+				values = append(values, v1)
+				i, j = i+1, j+1
+			}
+		}
+		values = append(append(values, c.values[i:]...), other.values[j:]...)
+		return &container{kind: arrayKind, n: len(values), values: values}
+	}
+	if c.kind == arrayKind {
+		c, other = other, c
+	}
+	u := c.clone()
+	u.toBitmap()
+	if other.kind == arrayKind {
+		for _, value := range other.values {
+			u.words[value/64] |= 1 << (value % 64)
+		}
+	} else {
+		for i, w := range other.words {
+			u.words[i] |= w
+		}
+	}
+	u.count()
+	return u
+}
+
+func (c *container) intersect(other *container) *container {
+	c, other = c.expanded(), other.expanded()
+	if c.kind == bitmapKind && other.kind == bitmapKind {
+		words := make([]uint64, 1024)
+		for i := range words {
+			words[i] = c.words[i] & other.words[i]
+		}
+		i := &container{kind: bitmapKind, words: words}
+		i.count()
+		return i
+	}
+	if c.kind == bitmapKind || (other.kind == arrayKind && other.n < c.n) {
+		c, other = other, c
+	}
+	values := make([]uint16, 0, c.n)
+	for _, value := range c.values {
+		if other.contains(value) {
+			values = append(values, value)
+		}
+	}
+	return &container{kind: arrayKind, n: len(values), values: values}
+}
+
+// count recounts a bitmap container, converting it to an array if it is small enough.
+func (c *container) count() {
+	c.n = 0
+	for _, w := range c.words {
+		c.n += bits.OnesCount64(w)
+	}
+	if c.n <= arrayMax {
+		c.toArray()
+	}
+}
+
+// Bitmap is a compressed set of uint32 keys, in the style of [Roaring bitmaps].
+// Keys are partitioned by their high 16 bits into containers of the low 16 bits,
+// which are sorted arrays if sparse, or bitmaps if dense.
+// [Bitmap.Optimize] converts containers to runs where that is smaller.
+// The zero value is an empty set ready to use.
+//
+// Keys are iterated in ascending order, so they are compatible with the Sorted* functions,
+// e.g., [SortedIntersect].
+//
+// [Roaring bitmaps]: https://roaringbitmap.org
+type Bitmap struct {
+	keys       []uint16
+	containers []*container
+}
+
+// NewBitmap returns a [Bitmap] of the keys.
+func NewBitmap(keys iter.Seq[uint32]) *Bitmap {
+	b := &Bitmap{}
+	b.Insert(keys)
+	return b
+}
+
+func (b *Bitmap) find(key uint32) (int, bool) {
+	return slices.BinarySearch(b.keys, uint16(key>>16))
+}
+
+// Len returns the number of keys.
+//
+// Performance:
+//   - time: O(min(n, 65536))
+func (b *Bitmap) Len() int {
+	count := 0
+	for _, c := range b.containers {
+		count += c.n
+	}
+	return count
+}
+
+// Contains returns whether the key is present.
+func (b *Bitmap) Contains(key uint32) bool {
+	i, found := b.find(key)
+	return found && b.containers[i].contains(uint16(key))
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (b *Bitmap) Missing(key uint32) bool {
+	return !b.Contains(key)
+}
+
+// Add key(s).
+func (b *Bitmap) Add(keys ...uint32) {
+	for _, key := range keys {
+		b.add(key)
+	}
+}
+
+func (b *Bitmap) add(key uint32) {
+	i, found := b.find(key)
+	if !found {
+		b.keys = slices.Insert(b.keys, i, uint16(key>>16))
+		b.containers = slices.Insert(b.containers, i, &container{})
+	}
+	b.containers[i].add(uint16(key))
+}
+
+// Insert keys.
+func (b *Bitmap) Insert(keys iter.Seq[uint32]) {
+	for key := range keys {
+		b.add(key)
+	}
+}
+
+// Delete key(s).
+func (b *Bitmap) Delete(keys ...uint32) {
+	for _, key := range keys {
+		b.delete(key)
+	}
+}
+
+func (b *Bitmap) delete(key uint32) {
+	i, found := b.find(key)
+	if found && b.containers[i].remove(uint16(key)) && b.containers[i].n == 0 {
+		b.keys = slices.Delete(b.keys, i, i+1)
+		b.containers = slices.Delete(b.containers, i, i+1)
+	}
+}
+
+// Remove keys.
+func (b *Bitmap) Remove(keys iter.Seq[uint32]) {
+	for key := range keys {
+		b.delete(key)
+	}
+}
+
+// All returns the keys in ascending order.
+func (b *Bitmap) All() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range b.containers {
+			high := uint32(b.keys[i]) << 16
+			for value := range c.all() {
+				if !yield(high | uint32(value)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Rank returns the number of keys < key.
+//
+// Performance:
+//   - time: O(min(n, 65536) + log n)
+func (b *Bitmap) Rank(key uint32) int {
+	i, found := b.find(key)
+	rank := 0
+	for _, c := range b.containers[:i] {
+		rank += c.n
+	}
+	if found {
+		rank += b.containers[i].rank(uint16(key))
+	}
+	return rank
+}
+
+// Select returns the key at the index in ascending order, and whether it exists.
+//
+// Performance:
+//   - time: O(min(n, 65536) + 1024)
+func (b *Bitmap) Select(index int) (uint32, bool) {
+	if index < 0 {
+		return 0, false
+	}
+	for i, c := range b.containers {
+		if index < c.n {
+			return uint32(b.keys[i])<<16 | uint32(c.at(index)), true
+		}
+		index -= c.n
+	}
+	return 0, false
+}
+
+// Optimize converts each container to runs, arrays, or bitmaps, whichever is smallest.
+// Containers are expanded again when they are modified.
+func (b *Bitmap) Optimize() {
+	for _, c := range b.containers {
+		c.optimize()
+	}
+}
+
+// Clone returns a copy.
+func (b *Bitmap) Clone() *Bitmap {
+	containers := make([]*container, len(b.containers))
+	for i, c := range b.containers {
+		containers[i] = c.clone()
+	}
+	return &Bitmap{slices.Clone(b.keys), containers}
+}
+
+// Union returns a new bitmap of the keys present in either.
+//
+// Performance:
+//   - time: O(n1+n2) for arrays, O(n1/64+n2/64) for bitmaps
+func (b *Bitmap) Union(other *Bitmap) *Bitmap {
+	u := &Bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(b.keys) && b.keys[i] < other.keys[j]):
+			u.keys = append(u.keys, b.keys[i])
+			u.containers = append(u.containers, b.containers[i].clone())
+			i += 1
+		case i == len(b.keys) || other.keys[j] < b.keys[i]:
+			u.keys = append(u.keys, other.keys[j])
+			u.containers = append(u.containers, other.containers[j].clone())
+			j += 1
+		default:
+			u.keys = append(u.keys, b.keys[i])
+			u.containers = append(u.containers, b.containers[i].union(other.containers[j]))
+			i, j = i+1, j+1
+		}
+	}
+	return u
+}
+
+// Intersect returns a new bitmap of the keys present in both.
+//
+// Performance:
+//   - time: O(min(n1, n2)) for arrays, O(n1/64+n2/64) for bitmaps
+func (b *Bitmap) Intersect(other *Bitmap) *Bitmap {
+	m := &Bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) && j < len(other.keys) {
+		switch {
+		case b.keys[i] < other.keys[j]:
+			i += 1
+		case b.keys[i] > other.keys[j]:
+			j += 1
+		default:
+			if c := b.containers[i].intersect(other.containers[j]); c.n > 0 {
+				m.keys = append(m.keys, b.keys[i])
+				m.containers = append(m.containers, c)
+			}
+			i, j = i+1, j+1
+		}
+	}
+	return m
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] with a portable little-endian format.
+// Each container is encoded as its high bits, kind, and length, followed by its
+// sorted values, 1024 bitmap words, or inclusive runs.
+func (b *Bitmap) MarshalBinary() ([]byte, error) {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(b.keys)))
+	for i, c := range b.containers {
+		data = binary.LittleEndian.AppendUint16(data, b.keys[i])
+		data = append(data, c.kind)
+		switch c.kind {
+		case arrayKind:
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(c.values)))
+			for _, value := range c.values {
+				data = binary.LittleEndian.AppendUint16(data, value)
+			}
+		case bitmapKind:
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(c.words)))
+			for _, w := range c.words {
+				data = binary.LittleEndian.AppendUint64(data, w)
+			}
+		case runKind:
+			data = binary.LittleEndian.AppendUint32(data, uint32(len(c.runs)))
+			for _, r := range c.runs {
+				data = binary.LittleEndian.AppendUint16(data, r[0])
+				data = binary.LittleEndian.AppendUint16(data, r[1])
+			}
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+// Returns [ErrEncoding] if the data is invalid.
+func (b *Bitmap) UnmarshalBinary(data []byte) error {
+	var u Bitmap
+	if len(data) < 4 {
+		return ErrEncoding
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+	for range count {
+		if len(data) < 7 {
+			return ErrEncoding
+		}
+		key, kind := binary.LittleEndian.Uint16(data), data[2]
+		size := int(binary.LittleEndian.Uint32(data[3:]))
+		data = data[7:]
+		if len(u.keys) > 0 && key <= u.keys[len(u.keys)-1] {
+			return ErrEncoding
+		}
+		c := &container{kind: kind}
+		switch {
+		case kind == arrayKind && size > 0 && size <= arrayMax && len(data) >= 2*size:
+			for i := range size {
+				value := binary.LittleEndian.Uint16(data[2*i:])
+				if i > 0 && value <= c.values[i-1] {
+					return ErrEncoding
+				}
+				c.values = append(c.values, value)
+			}
+			data = data[2*size:]
+			c.n = size
+		case kind == bitmapKind && size == 1024 && len(data) >= 8*size:
+			for i := range size {
+				c.words = append(c.words, binary.LittleEndian.Uint64(data[8*i:]))
+			}
+			data = data[8*size:]
+			c.count()
+		case kind == runKind && size > 0 && len(data) >= 4*size:
+			for i := range size {
+				r := [2]uint16{
+					binary.LittleEndian.Uint16(data[4*i:]), binary.LittleEndian.Uint16(data[4*i+2:]),
+				}
+				if r[0] > r[1] || (i > 0 && int(r[0]) <= int(c.runs[i-1][1])+1) {
+					return ErrEncoding
+				}
+				c.runs = append(c.runs, r)
+				c.n += int(r[1]-r[0]) + 1
+			}
+			data = data[4*size:]
+		default:
+			return ErrEncoding
+		}
+		if c.n == 0 {
+			return ErrEncoding
+		}
+		u.keys, u.containers = append(u.keys, key), append(u.containers, c)
+	}
+	if len(data) > 0 {
+		return ErrEncoding
+	}
+	*b = u
+	return nil
+}
@@ -3,9 +3,11 @@ package iterset
 import (
 	"cmp"
 	"context"
+	"fmt"
 	"iter"
 	"maps"
 	"slices"
+	"sort"
 )
 
 type iterable[V any] interface {
@@ -417,14 +419,19 @@ func Keys[K, V any](seq iter.Seq2[K, V]) iter.Seq[K] {
 //
 // Related:
 //   - [Compact] to deduplicate
+//   - [SortedMerge] for multiple sequences
 //
 // Performance:
 //   - time: O(k)
 func SortedUnion[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seq S) iter.Seq[K] {
-	return sortedUnionFunc(keys, seq, cmp.Compare)
+	return SortedUnionFunc(keys, seq, cmp.Compare)
 }
 
-func sortedUnionFunc[V any, S iterable[V]](
+// SortedUnionFunc is like [SortedUnion] but uses a comparison function.
+//
+// Performance:
+//   - time: O(k)
+func SortedUnionFunc[V any, S iter.Seq[V] | []V](
 	keys iter.Seq[V], values S, compare func(V, V) int,
 ) iter.Seq[V] {
 	return func(yield func(V) bool) {
@@ -448,18 +455,170 @@ func sortedUnionFunc[V any, S iterable[V]](
 	}
 }
 
-// SortedIntersect returns the intersection of sorted keys.
+// heap is a binary min-heap ordered by a less function.
+type heap[V any] struct {
+	values []V
+	less   func(V, V) bool
+	moved  func(V, int) // optional, to track the index of values
+}
+
+func (h *heap[V]) swap(i, j int) {
+	h.values[i], h.values[j] = h.values[j], h.values[i]
+	if h.moved != nil {
+		h.moved(h.values[i], i)
+		h.moved(h.values[j], j)
+	}
+}
+
+func (h *heap[V]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.values[i], h.values[parent]) {
+			return
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *heap[V]) down(i int) {
+	for {
+		child := 2*i + 1
+		if child >= len(h.values) {
+			return
+		}
+		if child+1 < len(h.values) && h.less(h.values[child+1], h.values[child]) {
+			child += 1
+		}
+		if !h.less(h.values[child], h.values[i]) {
+			return
+		}
+		h.swap(i, child)
+		i = child
+	}
+}
+
+// set replaces the value at the index, and restores the heap order.
+func (h *heap[V]) set(i int, value V) {
+	h.values[i] = value
+	if h.moved != nil {
+		h.moved(value, i)
+	}
+	h.down(i)
+	h.up(i)
+}
+
+func (h *heap[V]) push(value V) {
+	h.values = append(h.values, value)
+	h.set(len(h.values)-1, value)
+}
+
+func (h *heap[V]) pop() V {
+	value := h.values[0]
+	last := len(h.values) - 1
+	h.values[0] = h.values[last]
+	h.values = h.values[:last]
+	if last > 0 {
+		h.set(0, h.values[0])
+	}
+	return value
+}
+
+// SortedMerge returns the merged sorted keys of any number of sequences.
+// Duplicates are retained, as in [SortedUnion].
+//
+// Related:
+//   - [SortedMergeUnique] to deduplicate
+//
+// Performance:
+//   - time: O(k log n)
+//   - space: O(n)
+func SortedMerge[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seqs ...S) iter.Seq[K] {
+	return sortedMergeFunc(keys, seqs, cmp.Compare, false)
+}
+
+// SortedMergeUnique is like [SortedMerge] but returns each key only once.
+//
+// Performance:
+//   - time: O(k log n)
+//   - space: O(n)
+func SortedMergeUnique[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seqs ...S) iter.Seq[K] {
+	return sortedMergeFunc(keys, seqs, cmp.Compare, true)
+}
+
+func sortedMergeFunc[V any, S iterable[V]](
+	keys iter.Seq[V], seqs []S, compare func(V, V) int, unique bool,
+) iter.Seq[V] {
+	type head struct {
+		value V
+		index int
+		next  func() (V, bool)
+	}
+	less := func(a, b head) bool {
+		c := compare(a.value, b.value)
+		return c < 0 || (c == 0 && a.index < b.index)
+	}
+	return func(yield func(V) bool) {
+		h := heap[head]{values: make([]head, 0, len(seqs)+1), less: less}
+		push := func(index int, next func() (V, bool)) {
+			if value, ok := next(); ok {
+				h.push(head{value, index, next})
+			}
+		}
+		next, stop := pull[V](keys)
+		defer stop()
+		push(0, next)
+		for index, seq := range seqs {
+			next, stop := pull[V](seq)
+			defer stop()
+			push(index+1, next)
+		}
+		var last V
+		for count := 0; len(h.values) > 0; count += 1 {
+			top := &h.values[0]
+			if (!unique || count == 0 || compare(last, top.value) != 0) && !yield(top.value) {
+				return
+			}
+			last = top.value
+			if value, ok := top.next(); ok {
+				top.value = value
+				h.down(0)
+			} else {
+				h.pop()
+			}
+		}
+	}
+}
+
+// SortedIntersect returns the intersection of sorted keys with the sorted sequence(s).
 // Duplicates are matched one-to-one.
+// Multiple sequences leapfrog to the greatest current key, galloping through slices,
+// and stop when any is exhausted.
 //
 // Performance:
-//   - time: O(k)
-func SortedIntersect[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seq S) iter.Seq[K] {
-	return Keys(sortedIntersectFunc(keys, seq, cmp.Compare))
+//   - time: O(k) per sequence
+func SortedIntersect[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seqs ...S) iter.Seq[K] {
+	switch len(seqs) {
+	case 0:
+		return keys
+	case 1:
+		return Keys(SortedIntersectFunc(keys, seqs[0], cmp.Compare))
+	}
+	return sortedIntersectAll(keys, seqs, cmp.Compare)
 }
 
-func sortedIntersectFunc[K, V any, S iterable[V]](
+// SortedIntersectFunc is like [SortedIntersect] but uses a comparison function,
+// which may compare different types. Returns the matched pairs of keys and values.
+//
+// Performance:
+//   - time: O(k)
+//   - time: O(k log(n/k)) if slice is much larger
+func SortedIntersectFunc[K, V any, S iter.Seq[V] | []V](
 	keys iter.Seq[K], values S, compare func(K, V) int,
 ) iter.Seq2[K, V] {
+	if slc, ok := any(values).([]V); ok {
+		return sortedIntersectSlice(keys, slc, compare)
+	}
 	return func(yield func(K, V) bool) {
 		next, stop := pull[V](values)
 		defer stop()
@@ -480,18 +639,34 @@ func sortedIntersectFunc[K, V any, S iterable[V]](
 	}
 }
 
-// SortedDifference returns the difference of sorted keys.
+// SortedDifference returns the difference of sorted keys from the sorted sequence(s).
 // Duplicates are matched one-to-one.
+// Exhausted sequences are dropped, and the remaining keys pass through.
 //
 // Performance:
-//   - time: O(k)
-func SortedDifference[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seq S) iter.Seq[K] {
-	return sortedDifferenceFunc(keys, seq, cmp.Compare)
+//   - time: O(k) per sequence
+func SortedDifference[K cmp.Ordered, S iter.Seq[K] | []K](keys iter.Seq[K], seqs ...S) iter.Seq[K] {
+	switch len(seqs) {
+	case 0:
+		return keys
+	case 1:
+		return SortedDifferenceFunc(keys, seqs[0], cmp.Compare)
+	}
+	return sortedDifferenceAll(keys, seqs, cmp.Compare)
 }
 
-func sortedDifferenceFunc[K, V any, S iterable[V]](
+// SortedDifferenceFunc is like [SortedDifference] but uses a comparison function,
+// which may compare different types.
+//
+// Performance:
+//   - time: O(k)
+//   - time: O(k log(n/k)) if slice is much larger
+func SortedDifferenceFunc[K, V any, S iter.Seq[V] | []V](
 	keys iter.Seq[K], values S, compare func(K, V) int,
 ) iter.Seq[K] {
+	if slc, ok := any(values).([]V); ok {
+		return sortedDifferenceSlice(keys, slc, compare)
+	}
 	return func(yield func(K) bool) {
 		next, stop := pull[V](values)
 		defer stop()
@@ -509,6 +684,292 @@ func sortedDifferenceFunc[K, V any, S iterable[V]](
 	}
 }
 
+// SortedSymmetricDifference returns the sorted keys which are not in both,
+// with the index of the sequence each is from: 0 for keys, and 1 for seq.
+// Duplicates are matched one-to-one.
+//
+// Related:
+//   - [MapSet.SymmetricDifference] if either sequence was a map
+//
+// Performance:
+//   - time: O(k)
+//   - space: O(1)
+func SortedSymmetricDifference[K cmp.Ordered, S iter.Seq[K] | []K](
+	keys iter.Seq[K], seq S,
+) iter.Seq2[K, int] {
+	return SortedSymmetricDifferenceFunc(keys, seq, cmp.Compare)
+}
+
+// SortedSymmetricDifferenceFunc is like [SortedSymmetricDifference] but uses a comparison function.
+//
+// Performance:
+//   - time: O(k)
+//   - space: O(1)
+func SortedSymmetricDifferenceFunc[V any, S iter.Seq[V] | []V](
+	keys iter.Seq[V], values S, compare func(V, V) int,
+) iter.Seq2[V, int] {
+	return func(yield func(V, int) bool) {
+		next, stop := pull[V](values)
+		defer stop()
+		value, ok := next()
+		for key := range keys {
+			for ok && compare(key, value) > 0 {
+				if !yield(value, 1) {
+					return
+				}
+				value, ok = next()
+			}
+			if ok && compare(key, value) == 0 {
+				value, ok = next()
+			} else if !yield(key, 0) {
+				return
+			}
+		}
+		for ok && yield(value, 1) {
+			value, ok = next()
+		}
+	}
+}
+
+// UnsortedError reports a pair of consecutive keys which are out of order.
+type UnsortedError[K any] struct {
+	Prev, Next K
+}
+
+func (e *UnsortedError[K]) Error() string {
+	return fmt.Sprintf("iterset: keys are not sorted: %v before %v", e.Prev, e.Next)
+}
+
+// CheckSorted returns the keys, but panics with an [*UnsortedError] if they are out of order.
+// Wrap inputs to the Sorted* functions to detect unsorted input while streaming;
+// unwrapped inputs have no overhead.
+//
+// Related:
+//   - [slices.IsSorted] for a slice
+func CheckSorted[K cmp.Ordered](keys iter.Seq[K]) iter.Seq[K] {
+	return CheckSortedFunc(keys, cmp.Compare)
+}
+
+// CheckSortedFunc is like [CheckSorted] but uses a comparison function.
+//
+// Related:
+//   - [slices.IsSortedFunc] for a slice
+func CheckSortedFunc[K any](keys iter.Seq[K], compare func(K, K) int) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		var prev K
+		started := false
+		for key := range keys {
+			if started && compare(prev, key) > 0 {
+				panic(&UnsortedError[K]{prev, key})
+			}
+			if !yield(key) {
+				return
+			}
+			prev, started = key, true
+		}
+	}
+}
+
+// gallop is the number of linear probes before switching to exponential search.
+// In BenchmarkSortedGallop, probing is faster when there are up to about 8 values per key,
+// and slower by up to a third when keys are sparser, which exponential search bounds.
+const gallop = 8
+
+// seek returns the index of the first value from i which is not less than the key.
+// The first probes are linear; longer distances use exponential and binary search,
+// which adapts to the ratio of sizes without knowing the length of the keys.
+func seek[K, V any](values []V, i int, key K, compare func(K, V) int, probes int) int {
+	for end := min(i+probes, len(values)); i < end; i++ {
+		if compare(key, values[i]) <= 0 {
+			return i
+		}
+	}
+	lo, hi := i, i
+	for step := 1; hi < len(values) && compare(key, values[hi]) > 0; step *= 2 {
+		lo, hi = hi+1, hi+step
+	}
+	hi = min(hi, len(values))
+	return lo + sort.Search(hi-lo, func(j int) bool { return compare(key, values[lo+j]) <= 0 })
+}
+
+func sortedIntersectSlice[K, V any](
+	keys iter.Seq[K], values []V, compare func(K, V) int,
+) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		i := 0
+		for key := range keys {
+			if i = seek(values, i, key, compare, gallop); i == len(values) {
+				return
+			}
+			if compare(key, values[i]) == 0 {
+				if !yield(key, values[i]) {
+					return
+				}
+				i += 1
+			}
+		}
+	}
+}
+
+func sortedDifferenceSlice[K, V any](
+	keys iter.Seq[K], values []V, compare func(K, V) int,
+) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		i := 0
+		for key := range keys {
+			i = seek(values, i, key, compare, gallop)
+			if i < len(values) && compare(key, values[i]) == 0 {
+				i += 1
+			} else if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+type sortedHead[V any] struct {
+	value  V
+	next   func() (V, bool)
+	values []V // remaining values if the sequence is a slice, in which case next is nil
+}
+
+// step advances the head to its next value. Returns whether there was one.
+func (h *sortedHead[V]) step() (ok bool) {
+	if h.next != nil {
+		h.value, ok = h.next()
+		return ok
+	}
+	if len(h.values) == 0 {
+		return false
+	}
+	h.value, h.values = h.values[0], h.values[1:]
+	return true
+}
+
+// skip advances the head to the first value which is not less than the key,
+// galloping through slices. Returns whether there was one.
+func (h *sortedHead[V]) skip(key V, compare func(V, V) int) bool {
+	if compare(key, h.value) <= 0 {
+		return true
+	}
+	if h.next == nil {
+		i := seek(h.values, 0, key, compare, gallop)
+		if i == len(h.values) {
+			return false
+		}
+		h.value, h.values = h.values[i], h.values[i+1:]
+		return true
+	}
+	for compare(key, h.value) > 0 {
+		if !h.step() {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedHeads pulls the first value of each sequence.
+// Returns whether all were non-empty, and a function to stop them.
+func sortedHeads[V any, S iterable[V]](seqs []S) ([]sortedHead[V], bool, func()) {
+	heads := make([]sortedHead[V], 0, len(seqs))
+	stops := make([]func(), 0, len(seqs))
+	stop := func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}
+	all := true
+	for _, seq := range seqs {
+		if values, ok := any(seq).([]V); ok {
+			if len(values) > 0 {
+				heads = append(heads, sortedHead[V]{value: values[0], values: values[1:]})
+			} else {
+				all = false
+			}
+			continue
+		}
+		next, stop := pull[V](seq)
+		stops = append(stops, stop)
+		if value, ok := next(); ok {
+			heads = append(heads, sortedHead[V]{value: value, next: next})
+		} else {
+			all = false
+		}
+	}
+	return heads, all, stop
+}
+
+func sortedIntersectAll[V any, S iterable[V]](
+	keys iter.Seq[V], seqs []S, compare func(V, V) int,
+) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		heads, ok, stop := sortedHeads[V](seqs)
+		defer stop()
+		if !ok {
+			return
+		}
+		target := heads[0].value // lower bound for a match
+	keys:
+		for key := range keys {
+			if compare(key, target) < 0 {
+				continue
+			}
+			for i := range heads {
+				head := &heads[i]
+				if !head.skip(key, compare) {
+					return
+				}
+				if compare(head.value, key) > 0 {
+					target = head.value
+					// the mismatched head is checked first next time
+					heads[0], heads[i] = heads[i], heads[0]
+					continue keys
+				}
+			}
+			target = key
+			if !yield(key) {
+				return
+			}
+			for i := range heads {
+				head := &heads[i]
+				if !head.step() {
+					return
+				}
+				if compare(head.value, target) > 0 {
+					target = head.value
+				}
+			}
+		}
+	}
+}
+
+func sortedDifferenceAll[V any, S iterable[V]](
+	keys iter.Seq[V], seqs []S, compare func(V, V) int,
+) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		heads, _, stop := sortedHeads[V](seqs)
+		defer stop()
+		for key := range keys {
+			matched := false
+			for i := 0; i < len(heads); i++ {
+				head := &heads[i]
+				ok := head.skip(key, compare)
+				if ok && !matched && compare(key, head.value) == 0 {
+					matched = true
+					ok = head.step()
+				}
+				if !ok {
+					heads = slices.Delete(heads, i, i+1)
+					i -= 1
+				}
+			}
+			if !matched && !yield(key) {
+				return
+			}
+		}
+	}
+}
+
 func goChan[V any](ctx context.Context, seq iter.Seq[V], size int) <-chan V {
 	ch := make(chan V, size)
 	go func() {
@@ -0,0 +1,148 @@
+package iterset
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// SpaceSaving tracks the approximate most common keys of a stream with at most k counters.
+// When the counters are full, an untracked key replaces the key with the minimum count,
+// and inherits its count as the error.
+//
+// Counts are overestimates, by at most the total / k, so every key which occurs
+// more than total / k times is tracked. The summaries of partitions are mergeable.
+type SpaceSaving[K comparable] struct {
+	k     int
+	total int
+	index map[K]int
+	heap  heap[estimate[K]]
+}
+
+// estimate is a key with an upper bound on its count, and the maximum overestimate.
+type estimate[K any] struct {
+	key        K
+	count, err int
+}
+
+// NewSpaceSaving returns an empty [SpaceSaving] tracker with k counters.
+// Panics if k is not positive.
+//
+// Related:
+//   - [Max] of [Count] for the exact most common keys
+func NewSpaceSaving[K comparable](k int) *SpaceSaving[K] {
+	if k <= 0 {
+		panic("iterset: k must be positive")
+	}
+	s := &SpaceSaving[K]{k: k, index: map[K]int{}}
+	s.heap.less = func(e1, e2 estimate[K]) bool { return e1.count < e2.count }
+	s.heap.moved = func(e estimate[K], i int) { s.index[e.key] = i }
+	return s
+}
+
+// floor returns the minimum count when the counters are full, which bounds untracked keys.
+func (s *SpaceSaving[K]) floor() int {
+	if len(s.heap.values) < s.k {
+		return 0
+	}
+	return s.heap.values[0].count
+}
+
+func (s *SpaceSaving[K]) add(key K) {
+	s.total += 1
+	if i, ok := s.index[key]; ok {
+		e := s.heap.values[i]
+		s.heap.set(i, estimate[K]{key, e.count + 1, e.err})
+	} else if len(s.heap.values) < s.k {
+		s.heap.push(estimate[K]{key, 1, 0})
+	} else {
+		floor := s.floor()
+		delete(s.index, s.heap.values[0].key)
+		s.heap.set(0, estimate[K]{key, floor + 1, floor})
+	}
+}
+
+// Add key(s).
+//
+// Performance:
+//   - time: O(log k)
+func (s *SpaceSaving[K]) Add(keys ...K) {
+	for _, key := range keys {
+		s.add(key)
+	}
+}
+
+// Insert keys.
+func (s *SpaceSaving[K]) Insert(keys iter.Seq[K]) {
+	for key := range keys {
+		s.add(key)
+	}
+}
+
+// Len returns the number of tracked keys.
+func (s *SpaceSaving[K]) Len() int {
+	return len(s.heap.values)
+}
+
+// Total returns the number of keys added.
+func (s *SpaceSaving[K]) Total() int {
+	return s.total
+}
+
+// Estimate returns an upper bound on the count of the key, and the maximum overestimate.
+// The actual count is in [count - err, count]. Untracked keys are bounded by the minimum count.
+func (s *SpaceSaving[K]) Estimate(key K) (count, err int) {
+	if i, ok := s.index[key]; ok {
+		return s.heap.values[i].count, s.heap.values[i].err
+	}
+	floor := s.floor()
+	return floor, floor
+}
+
+// All returns the tracked keys and estimated counts, in descending order of counts.
+//
+// Performance:
+//   - time: O(k log k)
+func (s *SpaceSaving[K]) All() iter.Seq2[K, int] {
+	values := slices.SortedStableFunc(slices.Values(s.heap.values), func(e1, e2 estimate[K]) int {
+		return cmp.Compare(e2.count, e1.count)
+	})
+	return func(yield func(K, int) bool) {
+		for _, e := range values {
+			if !yield(e.key, e.count) {
+				return
+			}
+		}
+	}
+}
+
+// Merge adds the summary of another partition, in-place, keeping at most k counters.
+// Keys missing from either summary are bounded by its minimum count,
+// so the merged counts remain overestimates.
+//
+// Performance:
+//   - time: O((k1 + k2) log(k1 + k2))
+func (s *SpaceSaving[K]) Merge(other *SpaceSaving[K]) {
+	floor1, floor2 := s.floor(), other.floor()
+	merged := make(map[K]estimate[K], len(s.heap.values)+len(other.heap.values))
+	for _, e := range s.heap.values {
+		merged[e.key] = estimate[K]{e.key, e.count + floor2, e.err + floor2}
+	}
+	for _, e := range other.heap.values {
+		if m, ok := merged[e.key]; ok {
+			merged[e.key] = estimate[K]{e.key, m.count - floor2 + e.count, m.err - floor2 + e.err}
+		} else {
+			merged[e.key] = estimate[K]{e.key, e.count + floor1, e.err + floor1}
+		}
+	}
+	values := slices.SortedStableFunc(maps.Values(merged), func(e1, e2 estimate[K]) int {
+		return cmp.Compare(e2.count, e1.count)
+	})
+	s.total += other.total
+	s.heap.values = s.heap.values[:0]
+	clear(s.index)
+	for _, e := range values[:min(s.k, len(values))] {
+		s.heap.push(e)
+	}
+}
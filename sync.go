@@ -0,0 +1,378 @@
+package iterset
+
+import (
+	"hash/maphash"
+	"iter"
+	"maps"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// SyncMapSet is a [MapSet] guarded by a read-write mutex, safe for concurrent use.
+// The zero value is an empty set ready to use.
+//
+// Eager methods hold the lock while consuming their sequence, so a sequence must not access the
+// receiver. Lazy iterators lock each lookup individually, and iterate over a snapshot of the map
+// taken when iteration begins; the receiver may be modified during iteration.
+type SyncMapSet[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  MapSet[K, V]
+}
+
+// NewSyncMapSet returns a [SyncMapSet] which takes ownership of the map.
+func NewSyncMapSet[K comparable, V any](m map[K]V) *SyncMapSet[K, V] {
+	return &SyncMapSet[K, V]{m: m}
+}
+
+func (s *SyncMapSet[K, V]) read() func() {
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (s *SyncMapSet[K, V]) write() func() {
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = MapSet[K, V]{}
+	}
+	return s.mu.Unlock
+}
+
+func (s *SyncMapSet[K, V]) snapshot() MapSet[K, V] {
+	defer s.read()()
+	return maps.Clone(s.m)
+}
+
+func (s *SyncMapSet[K, V]) store(key K, value V) {
+	defer s.write()()
+	s.m[key] = value
+}
+
+// Len returns the number of keys.
+func (s *SyncMapSet[K, V]) Len() int {
+	defer s.read()()
+	return len(s.m)
+}
+
+// Get returns the value and whether the key is present.
+func (s *SyncMapSet[K, V]) Get(key K) (V, bool) {
+	defer s.read()()
+	value, ok := s.m[key]
+	return value, ok
+}
+
+// Clone returns a snapshot of the map.
+func (s *SyncMapSet[K, V]) Clone() MapSet[K, V] {
+	m := s.snapshot()
+	if m == nil {
+		m = MapSet[K, V]{}
+	}
+	return m
+}
+
+// All returns the key-value pairs of a snapshot.
+func (s *SyncMapSet[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, value := range s.snapshot() {
+			if !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Do calls the function with the map under the write lock,
+// for compound operations which must be atomic.
+func (s *SyncMapSet[K, V]) Do(f func(MapSet[K, V])) {
+	defer s.write()()
+	f(s.m)
+}
+
+// Contains returns whether the key is present.
+func (s *SyncMapSet[K, V]) Contains(key K) bool {
+	defer s.read()()
+	return s.m.Contains(key)
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (s *SyncMapSet[K, V]) Missing(key K) bool {
+	defer s.read()()
+	return s.m.Missing(key)
+}
+
+// IsSuperset returns whether all keys are present.
+func (s *SyncMapSet[K, V]) IsSuperset(keys iter.Seq[K]) bool {
+	defer s.read()()
+	return s.m.IsSuperset(keys)
+}
+
+// Equal returns whether the key sets are equivalent.
+func (s *SyncMapSet[K, V]) Equal(keys iter.Seq[K]) bool {
+	defer s.read()()
+	return s.m.Equal(keys)
+}
+
+// IsSubset returns whether every map key is present in keys.
+func (s *SyncMapSet[K, V]) IsSubset(keys iter.Seq[K]) bool {
+	defer s.read()()
+	return s.m.IsSubset(keys)
+}
+
+// IsDisjoint returns whether no keys are present.
+func (s *SyncMapSet[K, V]) IsDisjoint(keys iter.Seq[K]) bool {
+	defer s.read()()
+	return s.m.IsDisjoint(keys)
+}
+
+// Add key(s) with zero value.
+func (s *SyncMapSet[K, V]) Add(keys ...K) {
+	defer s.write()()
+	s.m.Add(keys...)
+}
+
+// AddIfMissing adds the key with the value only if it is not present.
+// Returns the current value, and whether it was added.
+func (s *SyncMapSet[K, V]) AddIfMissing(key K, value V) (V, bool) {
+	defer s.write()()
+	current, ok := s.m[key]
+	if ok {
+		return current, false
+	}
+	s.m[key] = value
+	return value, true
+}
+
+// Insert keys with default value.
+func (s *SyncMapSet[K, V]) Insert(keys iter.Seq[K], value V) {
+	defer s.write()()
+	s.m.Insert(keys, value)
+}
+
+// Delete key(s).
+func (s *SyncMapSet[K, V]) Delete(keys ...K) {
+	defer s.write()()
+	s.m.Delete(keys...)
+}
+
+// Remove keys.
+func (s *SyncMapSet[K, V]) Remove(keys iter.Seq[K]) {
+	defer s.write()()
+	s.m.Remove(keys)
+}
+
+// RemoveAll removes the keys only if all of them are present.
+// Returns whether they were removed. The keys are consumed once.
+func (s *SyncMapSet[K, V]) RemoveAll(keys iter.Seq[K]) bool {
+	defer s.write()()
+	values := slices.Collect(keys)
+	if !s.m.IsSuperset(slices.Values(values)) {
+		return false
+	}
+	s.m.Delete(values...)
+	return true
+}
+
+// Toggle removes present keys, and inserts missing keys.
+func (s *SyncMapSet[K, V]) Toggle(keys iter.Seq[K], value V) {
+	defer s.write()()
+	s.m.Toggle(keys, value)
+}
+
+// Keep only the keys present in both.
+func (s *SyncMapSet[K, V]) Keep(keys iter.Seq[K]) {
+	defer s.write()()
+	s.m.Keep(keys)
+}
+
+// Union merges a snapshot with successive inserts.
+func (s *SyncMapSet[K, V]) Union(seqs ...iter.Seq2[K, V]) MapSet[K, V] {
+	return s.snapshot().Union(seqs...)
+}
+
+// Intersect returns the ordered key-value pairs which are present in both.
+func (s *SyncMapSet[K, V]) Intersect(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key := range keys {
+			value, ok := s.Get(key)
+			if ok && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// IntersectCount returns the number of keys present in both.
+func (s *SyncMapSet[K, V]) IntersectCount(keys iter.Seq[K]) int {
+	defer s.read()()
+	return s.m.IntersectCount(keys)
+}
+
+// Difference returns the key-value pairs of a snapshot which are not present in the keys.
+func (s *SyncMapSet[K, V]) Difference(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		s.snapshot().Difference(keys)(yield)
+	}
+}
+
+// ReverseDifference returns the ordered keys which are not present in the map.
+func (s *SyncMapSet[K, V]) ReverseDifference(keys iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		keys(func(key K) bool { return s.Contains(key) || yield(key) })
+	}
+}
+
+// SymmetricDifference returns keys which are not in both, compared to a snapshot.
+func (s *SyncMapSet[K, V]) SymmetricDifference(keys iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		s.snapshot().SymmetricDifference(keys)(yield)
+	}
+}
+
+// Overlap returns the sizes of the intersection and differences:
+// left only, both, right only.
+func (s *SyncMapSet[K, V]) Overlap(keys iter.Seq[K]) (int, int, int) {
+	defer s.read()()
+	return s.m.Overlap(keys)
+}
+
+// ShardedMapSet is a concurrent [MapSet] partitioned by key hash across [SyncMapSet] shards,
+// which reduces lock contention between writers.
+//
+// Operations on a single key lock only its shard. Iteration is over each shard's snapshot in turn,
+// so it is not an atomic snapshot of the whole set.
+type ShardedMapSet[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []shard[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	SyncMapSet[K, V]
+	_ [64]byte // avoid false sharing between shards
+}
+
+// NewShardedMapSet returns an empty [ShardedMapSet] with n shards.
+// If n <= 0, the number of shards is [runtime.GOMAXPROCS].
+func NewShardedMapSet[K comparable, V any](n int) *ShardedMapSet[K, V] {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return &ShardedMapSet[K, V]{seed: maphash.MakeSeed(), shards: make([]shard[K, V], n)}
+}
+
+func (s *ShardedMapSet[K, V]) shard(key K) *SyncMapSet[K, V] {
+	return &s.shards[maphash.Comparable(s.seed, key)%uint64(len(s.shards))].SyncMapSet
+}
+
+// Len returns the number of keys.
+func (s *ShardedMapSet[K, V]) Len() int {
+	count := 0
+	for i := range s.shards {
+		count += s.shards[i].Len()
+	}
+	return count
+}
+
+// Get returns the value and whether the key is present.
+func (s *ShardedMapSet[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+// All returns the key-value pairs of each shard's snapshot.
+func (s *ShardedMapSet[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for i := range s.shards {
+			for key, value := range s.shards[i].All() {
+				if !yield(key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Contains returns whether the key is present.
+func (s *ShardedMapSet[K, V]) Contains(key K) bool {
+	return s.shard(key).Contains(key)
+}
+
+// Missing returns whether the key is not present.
+// Missing exists to pass as a function value, e.g. to [slices.DeleteFunc].
+func (s *ShardedMapSet[K, V]) Missing(key K) bool {
+	return s.shard(key).Missing(key)
+}
+
+// IsSuperset returns whether all keys are present.
+func (s *ShardedMapSet[K, V]) IsSuperset(keys iter.Seq[K]) bool {
+	return allFunc(keys, s.Contains)
+}
+
+// Add key(s) with zero value.
+func (s *ShardedMapSet[K, V]) Add(keys ...K) {
+	for _, key := range keys {
+		s.shard(key).Add(key)
+	}
+}
+
+// AddIfMissing adds the key with the value only if it is not present.
+// Returns the current value, and whether it was added.
+func (s *ShardedMapSet[K, V]) AddIfMissing(key K, value V) (V, bool) {
+	return s.shard(key).AddIfMissing(key, value)
+}
+
+// Insert keys with default value.
+func (s *ShardedMapSet[K, V]) Insert(keys iter.Seq[K], value V) {
+	for key := range keys {
+		s.shard(key).store(key, value)
+	}
+}
+
+// Delete key(s).
+func (s *ShardedMapSet[K, V]) Delete(keys ...K) {
+	for _, key := range keys {
+		s.shard(key).Delete(key)
+	}
+}
+
+// Remove keys.
+func (s *ShardedMapSet[K, V]) Remove(keys iter.Seq[K]) {
+	for key := range keys {
+		s.shard(key).Delete(key)
+	}
+}
+
+// Intersect returns the ordered key-value pairs which are present in both.
+func (s *ShardedMapSet[K, V]) Intersect(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key := range keys {
+			value, ok := s.Get(key)
+			if ok && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// Difference returns the key-value pairs which are not present in the keys.
+func (s *ShardedMapSet[K, V]) Difference(keys iter.Seq[K]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m := Set[K]()
+		for key := range keys {
+			if s.Contains(key) {
+				m.add(key)
+			}
+		}
+		for key, value := range s.All() {
+			if !m.Contains(key) && !yield(key, value) {
+				return
+			}
+		}
+	}
+}
+
+// ReverseDifference returns the ordered keys which are not present in the set.
+func (s *ShardedMapSet[K, V]) ReverseDifference(keys iter.Seq[K]) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		keys(func(key K) bool { return s.Contains(key) || yield(key) })
+	}
+}